@@ -0,0 +1,354 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+// Package client is a Go client library for the Daedalus API.
+//
+// A Client plays a single session at a time:
+// Awake starts the session, Move moves Icarus within it and Done ends it.
+// Errors reported by Daedalus can be tested with errors.Is against
+// the sentinel errors in mazelib, e.g. mazelib.ErrWall.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+// Default settings of a Client.
+const (
+	DefaultTimeout    = 10 * time.Second
+	DefaultMaxRetries = 3
+	DefaultBackoff    = 100 * time.Millisecond
+)
+
+// maxBackoff caps the wait between retries.
+const maxBackoff = 5 * time.Second
+
+// Client is a client of the Daedalus API. It is safe for concurrent use,
+// but the moves of concurrent callers are interleaved in the same session.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+	token      string
+	solver     string
+
+	mu      sync.Mutex
+	session string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient makes the Client send requests with hc instead of http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout sets the timeout of each attempt of a request.
+// A timeout <= 0 means no timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithRetries sets how many times a request is retried when it fails
+// with a network error or a 5xx response, and the initial wait between retries,
+// which doubles at each retry.
+// Awakenings and moves, which must not be applied twice, are retried
+// only if the connection could not be made and so they were never sent.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = n
+		c.backoff = backoff
+	}
+}
+
+// WithToken makes the Client authenticate itself with token as a bearer token.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithSolver makes the Client name itself solver when it awakes,
+// so that Daedalus records its runs under that name.
+func WithSolver(solver string) Option {
+	return func(c *Client) {
+		c.solver = solver
+	}
+}
+
+// New returns a new Client of the Daedalus server at baseURL, e.g. "http://127.0.0.1:8013".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		timeout:    DefaultTimeout,
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Session returns the ID of the current session, or "" if no session is in progress.
+func (c *Client) Session() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// Awake asks Daedalus for a new maze and returns the reply describing
+// Icarus's awakening location. The first call starts a new session.
+func (c *Client) Awake(ctx context.Context) (mazelib.Reply, error) {
+	q := make(url.Values)
+	if c.solver != "" {
+		q.Set("solver", c.solver)
+	}
+
+	var r mazelib.Reply
+	err := c.do(ctx, http.MethodGet, "/awake", q, nil, &r)
+	if err == nil && r.Session != "" {
+		c.mu.Lock()
+		c.session = r.Session
+		c.mu.Unlock()
+	}
+	return r, err
+}
+
+// Move moves Icarus one step in dir.
+// Reaching the treasure is not an error; it is reported by the Victory field of the reply.
+func (c *Client) Move(ctx context.Context, dir mazelib.Direction) (mazelib.Reply, error) {
+	if dir.String() == "" {
+		return mazelib.Reply{}, mazelib.ErrBadDirection
+	}
+
+	var r mazelib.Reply
+	err := c.do(ctx, http.MethodGet, "/move/"+dir.String(), nil, nil, &r)
+	return r, err
+}
+
+// MoveBatch moves Icarus along dirs atomically up to the first wall, victory or error.
+func (c *Client) MoveBatch(ctx context.Context, dirs []mazelib.Direction) (mazelib.BatchReply, error) {
+	req := mazelib.BatchMove{Directions: make([]string, len(dirs))}
+	for i, d := range dirs {
+		req.Directions[i] = d.String()
+	}
+
+	var r mazelib.BatchReply
+	err := c.do(ctx, http.MethodPost, "/moves", nil, req, &r)
+	if err == nil {
+		err = r.Err()
+	}
+	return r, err
+}
+
+// Done ends the current session.
+func (c *Client) Done(ctx context.Context) (mazelib.Reply, error) {
+	var r mazelib.Reply
+	err := c.do(ctx, http.MethodGet, "/done", nil, nil, &r)
+
+	c.mu.Lock()
+	c.session = ""
+	c.mu.Unlock()
+
+	return r, err
+}
+
+// Spectate returns what Icarus has revealed so far of his maze in the session
+// identified by id, or in the session started last on the server if id is empty.
+func (c *Client) Spectate(ctx context.Context, id string) (mazelib.Sighting, error) {
+	q := make(url.Values)
+	if id != "" {
+		q.Set("session", id)
+	}
+
+	var s mazelib.Sighting
+	err := c.do(ctx, http.MethodGet, "/spectate", q, nil, &s)
+	if err == nil {
+		err = s.Err()
+	}
+	return s, err
+}
+
+// Health checks once whether the server is up and accepts requests.
+func (c *Client) Health(ctx context.Context) error {
+	var h mazelib.Health
+	if _, err := c.attempt(ctx, http.MethodGet, c.baseURL+"/healthz", nil, &h); err != nil {
+		return err
+	}
+	if h.Status != mazelib.StatusOK {
+		return fmt.Errorf("client: server is not healthy: %q", h.Status)
+	}
+	return nil
+}
+
+// WaitReady polls the server every interval until it gets healthy or ctx is done.
+func (c *Client) WaitReady(ctx context.Context, interval time.Duration) error {
+	for {
+		err := c.Health(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("client: server is not ready: %w", err)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// do sends a request to path with query qualified by the current session,
+// retrying it if needed, and decodes the response into out.
+// If the response is an error reply, it returns the error described by it.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	if query == nil {
+		query = make(url.Values)
+	}
+	if id := c.Session(); id != "" {
+		query.Set("session", id)
+	}
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.attempt(ctx, method, u, payload, out)
+		if !retry || attempt >= c.maxRetries || ctx.Err() != nil || (!idempotent(path) && !unsent(err)) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		wait *= 2
+		if wait > maxBackoff {
+			wait = maxBackoff
+		}
+	}
+}
+
+// idempotent reports whether a request to path does no harm if Daedalus receives it twice.
+func idempotent(path string) bool {
+	switch path {
+	case "/healthz", "/done", "/spectate":
+		return true
+	}
+	return false
+}
+
+// unsent reports whether err tells that a request was never sent
+// because the connection to the server could not be made.
+func unsent(err error) bool {
+	var op *net.OpError
+	return errors.As(err, &op) && op.Op == "dial"
+}
+
+// attempt makes a single attempt of a request, and reports whether it is worth retrying.
+func (c *Client) attempt(ctx context.Context, method, u string, payload []byte, out interface{}) (bool, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	contents, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+
+	// start from scratch so that nothing is left over from a previous attempt
+	v := reflect.ValueOf(out).Elem()
+	v.Set(reflect.Zero(v.Type()))
+
+	if err := json.Unmarshal(contents, out); err != nil {
+		return resp.StatusCode >= 500, &ResponseError{StatusCode: resp.StatusCode, Body: contents, Err: err}
+	}
+
+	if r, ok := out.(*mazelib.Reply); ok {
+		if err := r.Err(); err != nil {
+			return resp.StatusCode >= 500 && r.Code == "", err
+		}
+	}
+
+	return false, nil
+}
+
+// ResponseError is an error reporting a response which is not a valid reply.
+type ResponseError struct {
+	StatusCode int
+	Body       []byte
+	Err        error
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("client: invalid response with status %d: %v", e.StatusCode, e.Err)
+}
+
+func (e *ResponseError) Unwrap() error { return e.Err }
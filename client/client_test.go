@@ -0,0 +1,208 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		failures int
+		retries  int
+		wantErr  bool
+	}{
+		{0, 0, false},
+		{2, 2, false},
+		{3, 2, true},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls <= tt.failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(mazelib.Reply{Session: "s"})
+		}))
+
+		c := New(ts.URL, WithRetries(tt.retries, time.Millisecond))
+		_, err := c.Done(context.Background())
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%d failures with %d retries: got error %v", tt.failures, tt.retries, err)
+		}
+		var rerr *ResponseError
+		if tt.wantErr && !errors.As(err, &rerr) {
+			t.Errorf("%d failures with %d retries: got %T; want *ResponseError", tt.failures, tt.retries, err)
+		}
+
+		ts.Close()
+	}
+}
+
+// roundTripFunc is an http.RoundTripper calling itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestRetryOnlyUnsent(t *testing.T) {
+	var (
+		errDial  = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		errReset = &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	)
+	unavailable := func(r *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("")), Request: r}
+	}
+
+	tests := []struct {
+		name      string
+		call      func(*Client) error
+		err       error
+		wantCalls int
+	}{
+		{"awake", func(c *Client) error { _, err := c.Awake(context.Background()); return err }, errDial, 3},
+		{"awake", func(c *Client) error { _, err := c.Awake(context.Background()); return err }, errReset, 1},
+		{"awake", func(c *Client) error { _, err := c.Awake(context.Background()); return err }, nil, 1},
+		{"move", func(c *Client) error { _, err := c.Move(context.Background(), mazelib.N); return err }, errDial, 3},
+		{"move", func(c *Client) error { _, err := c.Move(context.Background(), mazelib.N); return err }, errReset, 1},
+		{"move", func(c *Client) error { _, err := c.Move(context.Background(), mazelib.N); return err }, nil, 1},
+		{"done", func(c *Client) error { _, err := c.Done(context.Background()); return err }, errReset, 3},
+		{"done", func(c *Client) error { _, err := c.Done(context.Background()); return err }, nil, 3},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		hc := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls++
+			if tt.err != nil {
+				return nil, tt.err
+			}
+			return unavailable(r), nil
+		})}
+
+		c := New("http://daedalus", WithHTTPClient(hc), WithRetries(2, time.Millisecond))
+		if err := tt.call(c); err == nil {
+			t.Errorf("%s failing with %v: got no error", tt.name, tt.err)
+		}
+		if calls != tt.wantCalls {
+			t.Errorf("%s failing with %v: got %d attempts; want %d", tt.name, tt.err, calls, tt.wantCalls)
+		}
+	}
+}
+
+func TestSessionAndTypedErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/awake":
+			_ = json.NewEncoder(w).Encode(mazelib.Reply{Session: "s1"})
+		case "/move/up":
+			if r.URL.Query().Get("session") != "s1" {
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(mazelib.ErrorReply(mazelib.ErrNoSession))
+				return
+			}
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(mazelib.ErrorReply(mazelib.ErrWall))
+		}
+	}))
+	defer ts.Close()
+
+	c := New(ts.URL)
+	ctx := context.Background()
+
+	if _, err := c.Move(ctx, mazelib.N); !errors.Is(err, mazelib.ErrNoSession) {
+		t.Errorf("move before awake: got %v; want %v", err, mazelib.ErrNoSession)
+	}
+	if _, err := c.Awake(ctx); err != nil || c.Session() != "s1" {
+		t.Fatalf("awake: got session %q, %v", c.Session(), err)
+	}
+	if _, err := c.Move(ctx, mazelib.N); !errors.Is(err, mazelib.ErrWall) {
+		t.Errorf("move into a wall: got %v; want %v", err, mazelib.ErrWall)
+	}
+	if _, err := c.Move(ctx, 0); !errors.Is(err, mazelib.ErrBadDirection) {
+		t.Errorf("move in no direction: got %v; want %v", err, mazelib.ErrBadDirection)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := New(ts.URL, WithRetries(10, time.Hour))
+	if _, err := c.Awake(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v; want %v", err, context.Canceled)
+	}
+}
+
+func TestTokenOverTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(mazelib.ErrorReply(mazelib.ErrUnauthorized))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(mazelib.Reply{Session: "s"})
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+
+	c := New(ts.URL, WithHTTPClient(ts.Client()))
+	if _, err := c.Awake(ctx); !errors.Is(err, mazelib.ErrUnauthorized) {
+		t.Errorf("without token: got %v; want %v", err, mazelib.ErrUnauthorized)
+	}
+
+	c = New(ts.URL, WithHTTPClient(ts.Client()), WithToken("secret"))
+	if _, err := c.Awake(ctx); err != nil {
+		t.Errorf("with token: got %v", err)
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	tests := []struct {
+		unhealthy int
+		timeout   time.Duration
+		wantErr   bool
+	}{
+		{0, time.Second, false},
+		{3, time.Second, false},
+		{1000, 50 * time.Millisecond, true},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls <= tt.unhealthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(mazelib.Health{Status: mazelib.StatusOK})
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+		err := New(ts.URL).WaitReady(ctx, time.Millisecond)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("unhealthy for %d checks: got error %v", tt.unhealthy, err)
+		}
+
+		cancel()
+		ts.Close()
+	}
+}
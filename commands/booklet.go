@@ -0,0 +1,96 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Defining the booklet command.
+// This will be called as 'laybrinth booklet'
+var bookletCmd = &cobra.Command{
+	Use:   "booklet",
+	Short: "Print laybrinths as a PDF booklet",
+	Long: `Booklet generates --count laybrinths in the same way as Daedalus does
+and lays them out one per page as a PDF document, followed by
+the answer key: a page with a shortest path for each of them.
+
+The laybrinths are generated from --seed, --width, --height and
+--braid, so a booklet is as difficult as the game with the same flags.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, _ := cmd.Flags().GetInt("count")
+		if n <= 0 {
+			return fmt.Errorf("count must be positive: %d", n)
+		}
+		if alg, _ := cmd.Flags().GetString("algorithm"); alg != algorithmBacktracker {
+			return fmt.Errorf("unknown algorithm %q", alg)
+		}
+
+		out, _ := cmd.Flags().GetString("output")
+		if out == "-" {
+			return writeBooklet(os.Stdout, n)
+		}
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		if err := writeBooklet(f, n); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	},
+}
+
+func init() {
+	bookletCmd.Flags().StringP("output", "o", "booklet.pdf", `file to write the booklet to ("-" for standard output)`)
+	bookletCmd.Flags().IntP("count", "n", 10, "number of laybrinths in the booklet")
+	bookletCmd.Flags().String("algorithm", algorithmBacktracker, "algorithm to generate the laybrinths with")
+	RootCmd.AddCommand(bookletCmd)
+}
+
+// writeBooklet generates n mazes of --width and --height from --seed
+// and writes them to w as a PDF booklet with the answer key at the end.
+func writeBooklet(w io.Writer, n int) error {
+	return mazelib.RenderPDF(w, bookletPages(n))
+}
+
+// bookletPages returns the pages of n mazes followed by their answers.
+func bookletPages(n int) []mazelib.PDFPage {
+	seeds := &seeder{base: viper.GetInt64("seed")}
+	x, y := viper.GetInt("width"), viper.GetInt("height")
+
+	pages := make([]mazelib.PDFPage, 2*n)
+	for i := 0; i < n; i++ {
+		d := createMaze(x, y, seeds.next()).data()
+		pages[i] = mazelib.PDFPage{
+			Title: fmt.Sprintf("Maze %d (%dx%d, seed %d)", i+1, d.Width, d.Height, d.Seed),
+			Maze:  d,
+		}
+		pages[n+i] = mazelib.PDFPage{
+			Title:    fmt.Sprintf("Answer %d", i+1),
+			Maze:     d,
+			Solution: true,
+		}
+	}
+	return pages
+}
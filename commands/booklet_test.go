@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestWriteBooklet(t *testing.T) {
+	for k, v := range map[string]int{"seed": 42, "width": 6, "height": 4} {
+		defer viper.Set(k, viper.Get(k))
+		viper.Set(k, v)
+	}
+
+	var a, b bytes.Buffer
+	if err := writeBooklet(&a, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeBooklet(&b, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Error("booklets from the same seed should be identical")
+	}
+
+	out := a.String()
+	if got := strings.Count(out, "/Type /Page "); got != 6 {
+		t.Errorf("got %d pages; want 6", got)
+	}
+	for _, want := range []string{"(Maze 1 \\(6x4, seed 42\\))", "(Maze 3 \\(6x4, seed 44\\))", "(Answer 3)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("%s is missing", want)
+		}
+	}
+	if i, j := strings.Index(out, "(Maze 3"), strings.Index(out, "(Answer 1)"); i > j {
+		t.Error("the answers should follow the mazes")
+	}
+}
+
+func TestBookletUnseeded(t *testing.T) {
+	for k, v := range map[string]int{"seed": 0, "width": 8, "height": 6} {
+		defer viper.Set(k, viper.Get(k))
+		viper.Set(k, v)
+	}
+
+	const n = 10
+	pages := bookletPages(n)
+	seen := make(map[string]int)
+	for i, p := range pages[:n] {
+		d := p.Maze
+		d.Seed = 0
+		b, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if j, ok := seen[string(b)]; ok {
+			t.Errorf("mazes %d and %d are the same", j+1, i+1)
+		}
+		seen[string(b)] = i
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/spf13/cobra"
+)
+
+// mazeFormat is a text format of mazes.
+type mazeFormat struct {
+	marshal   func(mazelib.MazeData) ([]byte, error)
+	unmarshal func([]byte) (mazelib.MazeData, error)
+}
+
+// mazeFormats are the formats of mazes convert supports, keyed by their names.
+var mazeFormats = map[string]mazeFormat{
+	"json": {mazelib.MarshalMaze, mazelib.UnmarshalMaze},
+	"ascii": {
+		func(d mazelib.MazeData) ([]byte, error) { return mazelib.MarshalASCII(d), nil },
+		mazelib.UnmarshalASCII,
+	},
+	"print": {
+		func(d mazelib.MazeData) ([]byte, error) { return mazelib.MarshalPrint(d), nil },
+		mazelib.UnmarshalPrint,
+	},
+}
+
+// mazeFormatNames returns the names of mazeFormats in order.
+func mazeFormatNames() []string {
+	names := make([]string, 0, len(mazeFormats))
+	for name := range mazeFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// detectMazeFormat guesses the format of a maze from its first character.
+func detectMazeFormat(b []byte) (string, error) {
+	switch s := bytes.TrimSpace(b); {
+	case len(s) == 0:
+		return "", errors.New("empty input")
+	case s[0] == '{':
+		return "json", nil
+	case s[0] == '+':
+		return "ascii", nil
+	case s[0] == '_':
+		return "print", nil
+	default:
+		return "", errors.New("unknown format of the input; specify it with --from")
+	}
+}
+
+// Defining the convert command.
+// This will be called as 'laybrinth convert'
+var convertCmd = &cobra.Command{
+	Use:   "convert [file]",
+	Short: "Convert a laybrinth from one format to another",
+	Long: `Convert reads a laybrinth from file, or from standard input if file is
+omitted or "-", and writes it in another format to standard output.
+
+Formats are:
+  json   the versioned JSON format read by --maze-file
+  ascii  the classic ASCII grid, e.g. +---+, with S at the start and T at the treasure
+  print  the characters the laybrinth is printed with
+
+The input format is detected unless it is given by --from.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			in  []byte
+			err error
+		)
+		if len(args) == 0 || args[0] == "-" {
+			in, err = io.ReadAll(os.Stdin)
+		} else {
+			in, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return err
+		}
+
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		if from == "" {
+			if from, err = detectMazeFormat(in); err != nil {
+				return err
+			}
+		}
+
+		src, ok := mazeFormats[from]
+		if !ok {
+			return fmt.Errorf("unknown format %q; want one of %s", from, strings.Join(mazeFormatNames(), ", "))
+		}
+		dst, ok := mazeFormats[to]
+		if !ok {
+			return fmt.Errorf("unknown format %q; want one of %s", to, strings.Join(mazeFormatNames(), ", "))
+		}
+
+		d, err := src.unmarshal(in)
+		if err != nil {
+			return err
+		}
+		out, err := dst.marshal(d)
+		if err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(out, []byte("\n")) {
+			out = append(out, '\n')
+		}
+		_, err = os.Stdout.Write(out)
+		return err
+	},
+}
+
+func init() {
+	convertCmd.Flags().String("from", "", "format of the input: json, ascii or print (detected if empty)")
+	convertCmd.Flags().String("to", "ascii", "format of the output: json, ascii or print")
+	RootCmd.AddCommand(convertCmd)
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMazeFormats(t *testing.T) {
+	want := createMaze(15, 10, 1).data()
+	want.Algorithm, want.Seed = "", 0 // not kept in text
+
+	for name, f := range mazeFormats {
+		b, err := f.marshal(want)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+
+		if got, err := detectMazeFormat(b); got != name || err != nil {
+			t.Errorf("%s: detected as %q, %v", name, got, err)
+		}
+
+		got, err := f.unmarshal(b)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v; want %+v", name, got, want)
+		}
+	}
+}
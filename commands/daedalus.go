@@ -16,12 +16,21 @@
 package commands
 
 import (
+	"context"
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -29,6 +38,8 @@ import (
 	"github.com/skatsuta/labyrinth/mazelib"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Maze is a maze.
@@ -38,16 +49,31 @@ type Maze struct {
 	end        mazelib.Coordinate
 	icarus     mazelib.Coordinate
 	StepsTaken int
+	// visits counts how many times Icarus has entered each room.
+	visits [][]int
+	// seed and algorithm describe how the maze was generated.
+	seed      int64
+	algorithm string
 }
 
-// Tracking the current maze being solved
+// algorithmBacktracker is the name of the algorithm createMaze generates mazes with.
+const algorithmBacktracker = "recursive-backtracker"
+
+// shortestPath returns the number of steps of the shortest path
+// from the starting point to the treasure, or -1 if there is no path.
+func (m *Maze) shortestPath() int {
+	return mazelib.Distances(m, m.start.X, m.start.Y)[m.end.Y][m.end.X]
+}
+
+// solved reports whether Icarus has reached the treasure.
+func (m *Maze) solved() bool {
+	return m.icarus == m.end
+}
+
+// shutdownTimeout is how long the server waits for in-flight requests
+// to finish when it is shut down.
+const shutdownTimeout = 5 * time.Second
 
-// WARNING: This approach is not safe for concurrent use
-// This server is only intended to have a single client at a time
-// We would need a different and more complex approach if we wanted
-// concurrent connections than these simple package variables
-var currentMaze *Maze
-var scores []int
 var debug bool
 
 // Defining the daedalus command.
@@ -59,128 +85,539 @@ var daedalusCmd = &cobra.Command{
 	Long: `Daedalus's job is to create a challenging Labyrinth for his opponent
   Icarus to solve.
 
-  Daedalus runs a server which Icarus clients can connect to to solve laybrinths.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		RunServer()
+  Daedalus runs a server which Icarus clients can connect to to solve laybrinths.
+
+  Open /dashboard on the server in a browser to watch Icarus in every
+  active session live. With --token, add it to the page as ?token=....`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		return RunServer(ctx, nil)
 	},
 }
 
 func init() {
-	rand.Seed(time.Now().UTC().UnixNano()) // need to initialize the seed
 	gin.SetMode(gin.ReleaseMode)
 
 	RootCmd.AddCommand(daedalusCmd)
 }
 
-// RunServer runs the web server.
-func RunServer() {
-	// Adding handling so that even when ctrl+c is pressed we still print
-	// out the results prior to exiting.
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+// RunServer runs the web server until ctx is done.
+// It closes ready, if not nil, once the server accepts connections.
+// Sessions without any request for --session-ttl are ended meanwhile.
+// Once ctx is done, it shuts down the server gracefully and flushes
+// the results of the sessions still in progress.
+func RunServer(ctx context.Context, ready chan<- struct{}) error {
+	certFile, keyFile := viper.GetString("tls-cert"), viper.GetString("tls-key")
+	if (certFile == "") != (keyFile == "") {
+		return errors.New("both --tls-cert and --tls-key are required to enable TLS")
+	}
+
+	sink, err := resultsSink(os.Stdout)
+	if err != nil {
+		return err
+	}
+	s := newServer(sink)
+	s.logger = log.FromContext(ctx)
+	s.token = viper.GetString("token")
+	s.seeds.base = viper.GetInt64("seed")
+	if s.renderer, err = mazeRenderer(); err != nil {
+		return err
+	}
+	if s.template, err = loadMazeFile(viper.GetString("maze-file")); err != nil {
+		return err
+	}
+
+	host := viper.GetString("host")
+	srv := &http.Server{
+		Addr:    net.JoinHostPort(host, viper.GetString("port")),
+		Handler: s.handler(),
+	}
+	srv.RegisterOnShutdown(s.stopStreams)
+
+	lis, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	var (
+		gs   *grpc.Server
+		glis net.Listener
+	)
+	if port := viper.GetInt("grpc-port"); port > 0 {
+		var opts []grpc.ServerOption
+		if certFile != "" {
+			creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+			if err != nil {
+				_ = lis.Close()
+				return err
+			}
+			opts = append(opts, grpc.Creds(creds))
+		}
+
+		glis, err = net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			_ = lis.Close()
+			return err
+		}
+		gs = newGRPCServer(s, opts...)
+	}
+
+	if ttl := viper.GetDuration("session-ttl"); ttl > 0 {
+		ectx, cancel := context.WithCancel(ctx)
+		expired := make(chan struct{})
+		go func() {
+			s.expireIdle(ectx, ttl)
+			close(expired)
+		}()
+		// the results of the sessions being expired must be flushed before exiting
+		defer func() {
+			cancel()
+			<-expired
+		}()
+	}
+
+	errc := make(chan error, 2)
+	go func() {
+		if certFile != "" {
+			errc <- srv.ServeTLS(lis, certFile, keyFile)
+			return
+		}
+		errc <- srv.Serve(lis)
+	}()
+	if gs != nil {
+		go func() {
+			errc <- gs.Serve(glis)
+		}()
+	}
+
+	// The listeners are open, so connections are queued until they are served.
+	if ready != nil {
+		close(ready)
+	}
+
+	select {
+	case err := <-errc:
+		_ = srv.Close()
+		if gs != nil {
+			gs.Stop()
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if gs != nil {
+		stopGRPC(sctx, gs)
+	}
+	err = srv.Shutdown(sctx)
+
+	// Even when ctrl+c is pressed we still flush the results prior to exiting.
+	s.flushAll()
+
+	return err
+}
+
+// stopGRPC stops gs gracefully, or forcibly once ctx is done.
+func stopGRPC(ctx context.Context, gs *grpc.Server) {
+	done := make(chan struct{})
 	go func() {
-		<-c
-		printResults()
-		os.Exit(1)
+		gs.GracefulStop()
+		close(done)
 	}()
 
+	select {
+	case <-done:
+	case <-ctx.Done():
+		gs.Stop()
+	}
+}
+
+// server is a Daedalus server which hosts labyrinth sessions for Icarus clients.
+type server struct {
+	sessions *sessionStore
+	sink     ResultsSink
+	seeds    seeder
+	// template is the maze every awakening is served, or nil to generate new ones.
+	template *mazelib.MazeData
+	// token is the bearer token clients must present. If empty, no token is required.
+	token string
+	// out is where mazes are printed to by renderer.
+	out      io.Writer
+	renderer mazelib.Renderer
+	logger   *log.Logger
+	// stop is closed when the server shuts down to end the event streams.
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// seeder hands out the seeds of mazes: base, base+1, and so on.
+// If base is 0, it is drawn at random on the first seed, so the seeds
+// differ even if mazes are created at the same clock reading.
+type seeder struct {
+	base int64
+	once sync.Once
+	n    atomic.Int64
+}
+
+// next returns the seed of the next maze.
+func (s *seeder) next() int64 {
+	s.once.Do(func() {
+		if s.base == 0 {
+			s.base = randomSeed()
+		}
+	})
+	return s.base + s.n.Add(1) - 1
+}
+
+// randomSeed returns a random positive seed,
+// which leaves room to count up from without overflowing.
+func randomSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return time.Now().UnixNano()
+	}
+	return int64(binary.BigEndian.Uint64(b[:])>>2) + 1
+}
+
+// newServer returns a new server which flushes the results of sessions to sink.
+// The sessions keep the move logs of their mazes only if sink saves them,
+// and trace their mazes only if sink is or holds a TraceSink.
+func newServer(sink ResultsSink) *server {
+	sessions := newSessionStore()
+	sessions.replays = savesReplays(sink)
+	s := &server{
+		sessions: sessions,
+		sink:     sink,
+		out:      os.Stdout,
+		logger:   log.Default(),
+		stop:     make(chan struct{}),
+	}
+	if sinks := traceSinks(sink); len(sinks) > 0 {
+		sessions.traced = func(id string, n int, t mazelib.Trace) {
+			for _, ts := range sinks {
+				if err := ts.Trace(id, n, t); err != nil {
+					s.logger.With("session", id).Errorf("error saving the trace of maze %d: %v\n", n, err)
+				}
+			}
+		}
+	}
+	return s
+}
+
+// printMaze prints the maze of sess overlaid with the path of Icarus.
+// The caller must hold sess.mu.
+func (s *server) printMaze(sess *session) {
+	r := s.renderer
+	r.Path = sess.path()
+	if err := r.Render(s.out, sess.maze); err != nil {
+		s.logger.Errorf("Failed to print the maze: %v\n", err)
+	}
+}
+
+// mazeRenderer returns the renderer configured by --maze-style and --color.
+func mazeRenderer() (mazelib.Renderer, error) {
+	name := viper.GetString("maze-style")
+	style, ok := mazelib.Styles[name]
+	if !ok {
+		return mazelib.Renderer{}, fmt.Errorf("unknown maze style %q", name)
+	}
+	return mazelib.Renderer{Style: style, Color: viper.GetBool("color")}, nil
+}
+
+// handler returns an http.Handler which routes requests to s.
+func (s *server) handler() http.Handler {
 	// Using gin-gonic/gin to handle our routing
-	r := gin.Default()
-	v1 := r.Group("/")
+	r := gin.New()
+	r.Use(s.accessLog, gin.Recovery())
+	r.GET("/healthz", Healthz)
+	v1 := r.Group("/", s.authorize)
+	{
+		v1.GET("/awake", s.GetStartingPoint)
+		v1.GET("/move/:direction", s.MoveDirection)
+		v1.POST("/moves", s.MoveBatch)
+		v1.GET("/done", s.End)
+		v1.GET("/play", s.Play)
+		v1.GET("/spectate", s.Spectate)
+	}
+	dashboard := r.Group("/", s.authorizeStream)
 	{
-		v1.GET("/awake", GetStartingPoint)
-		v1.GET("/move/:direction", MoveDirection)
-		v1.GET("/done", End)
+		dashboard.GET("/dashboard", s.Dashboard)
+		dashboard.GET("/events", s.Events)
+	}
+	return r
+}
+
+// accessLog is a middleware which logs every request through the logger of s.
+func (s *server) accessLog(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	l := s.logger.With(
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"status", c.Writer.Status(),
+		"latency", time.Since(start),
+		"client", c.ClientIP(),
+	)
+	if id := c.Query("session"); id != "" {
+		l = l.With("session", id)
+	}
+	l.Infof("%s %s", c.Request.Method, c.Request.URL.Path)
+}
+
+// Healthz returns the API response to the /healthz address,
+// which reports that the server is up and accepts requests.
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, mazelib.Health{Status: mazelib.StatusOK})
+}
+
+// authorize is a middleware which rejects requests without the bearer token of s.
+func (s *server) authorize(c *gin.Context) {
+	if !s.authorized(c.GetHeader("Authorization")) {
+		r, code := errorReply(mazelib.ErrUnauthorized)
+		c.AbortWithStatusJSON(code, r)
 	}
+}
 
-	if e := r.Run(":" + viper.GetString("port")); e != nil {
-		panic(e)
+// authorized reports whether the value of an Authorization header is valid for s.
+func (s *server) authorized(auth string) bool {
+	if s.token == "" {
+		return true
 	}
+	want := "Bearer " + s.token
+	return subtle.ConstantTimeCompare([]byte(auth), []byte(want)) == 1
 }
 
-// End ends a session and prints the results.
-// Called by Icarus when he has reached
-//   the number of times he wants to solve the laybrinth.
-func End(c *gin.Context) {
-	printResults()
-	os.Exit(1)
+// flush sends the results of sess to the sink of s.
+func (s *server) flush(sess *session) {
+	if err := s.sink.Flush(sess.results()); err != nil {
+		s.logger.With("session", sess.id).Errorf("error flushing results: %v\n", err)
+	}
 }
 
-// GetStartingPoint initializes a new maze and places Icarus in his awakening location
-func GetStartingPoint(c *gin.Context) {
-	ySize := viper.GetInt("height")
-	xSize := viper.GetInt("width")
-	initializeMaze(xSize, ySize)
-	startRoom, err := currentMaze.Discover(currentMaze.Icarus())
-	if err != nil {
-		fmt.Println("Icarus is outside of the maze. This shouldn't ever happen")
-		fmt.Println(err)
-		os.Exit(-1)
+// expireIdle ends the sessions without any request for ttl and flushes their results
+// until ctx is done.
+func (s *server) expireIdle(ctx context.Context, ttl time.Duration) {
+	t := time.NewTicker(max(ttl/2, time.Millisecond))
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			s.expire(now.Add(-ttl))
+		}
 	}
-	mazelib.PrintMaze(currentMaze)
+}
+
+// expire ends the sessions inactive since before and flushes their results.
+func (s *server) expire(before time.Time) {
+	for _, sess := range s.sessions.expire(before) {
+		s.logger.With("session", sess.id).Infof("Ending the session idle since %s\n", sess.active.Format(time.RFC3339))
+		s.flush(sess)
+	}
+}
+
+// flushAll ends all the sessions in progress and flushes their results.
+func (s *server) flushAll() {
+	for _, sess := range s.sessions.drain() {
+		s.flush(sess)
+	}
+}
+
+// End ends the calling session and flushes its results.
+// Called by Icarus when he has reached the number of times
+// he wants to solve the laybrinth.
+func (s *server) End(c *gin.Context) {
+	r, code := s.end(c.Query("session"))
+	c.JSON(code, r)
+}
 
-	c.JSON(http.StatusOK, mazelib.Reply{Survey: startRoom})
+// GetStartingPoint initializes a new maze and places Icarus in his awakening location.
+// If the request does not specify a session, it starts a new one.
+func (s *server) GetStartingPoint(c *gin.Context) {
+	r, code := s.awake(c.Query("session"), c.Query("solver"))
+	c.JSON(code, r)
 }
 
 // MoveDirection returns the API response to the /move/:direction address
-func MoveDirection(c *gin.Context) {
-	var err error
+func (s *server) MoveDirection(c *gin.Context) {
+	r, code := s.move(c.Query("session"), c.Param("direction"))
+	c.JSON(code, r)
+}
 
-	switch c.Param("direction") {
-	case "left":
-		err = currentMaze.MoveLeft()
-	case "right":
-		err = currentMaze.MoveRight()
-	case "down":
-		err = currentMaze.MoveDown()
-	case "up":
-		err = currentMaze.MoveUp()
+// MoveBatch returns the API response to the /moves address.
+// The request body is a mazelib.BatchMove listing the directions to move along.
+func (s *server) MoveBatch(c *gin.Context) {
+	var req mazelib.BatchMove
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, mazelib.BatchReply{Reply: mazelib.ErrorReply(err)})
+		return
 	}
 
-	var r mazelib.Reply
+	r, code := s.moveBatch(c.Query("session"), req.Directions)
+	c.JSON(code, r)
+}
 
+// awake initializes a new maze in the session identified by id and
+// places Icarus in his awakening location. If id is empty, it starts a new session.
+// solver, if not empty, names the solver in the run history.
+// It returns the reply to Icarus along with the corresponding HTTP status code.
+func (s *server) awake(id, solver string) (mazelib.Reply, int) {
+	var sess *session
+	if id != "" {
+		var found bool
+		if sess, found = s.sessions.use(id); !found {
+			return errorReply(mazelib.ErrNoSession)
+		}
+	} else {
+		var err error
+		if sess, err = s.sessions.create(); err != nil {
+			return errorReply(err)
+		}
+	}
+
+	ySize := viper.GetInt("height")
+	xSize := viper.GetInt("width")
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if solver != "" {
+		sess.solver = solver
+	}
+	var m *Maze
+	if s.template != nil {
+		m = mazeFromData(*s.template)
+	} else {
+		m = createMaze(xSize, ySize, s.seeds.next())
+	}
+	sess.start(m)
+	startRoom, err := sess.maze.Discover(sess.maze.Icarus())
 	if err != nil {
-		r.Error = true
-		r.Message = err.Error()
-		c.JSON(409, r)
-		return
+		s.logger.With("session", sess.id).Errorf("Icarus is outside of the maze. This shouldn't ever happen: %v\n", err)
+		return errorReply(err)
 	}
+	s.printMaze(sess)
 
-	s, e := currentMaze.LookAround()
+	r := mazelib.Reply{Survey: startRoom, Session: sess.id, Seed: sess.maze.seed}
+	sess.trace.Awake = r
+	return r, http.StatusOK
+}
 
-	if e != nil {
-		if e == mazelib.ErrVictory {
-			scores = append(scores, currentMaze.StepsTaken)
-			r.Victory = true
-			r.Message = fmt.Sprintf("Victory achieved in %d steps \n", currentMaze.StepsTaken)
-		} else {
-			r.Error = true
-			r.Message = err.Error()
+// move moves Icarus one step in direction in the session identified by id.
+// It returns the reply to Icarus along with the corresponding HTTP status code.
+func (s *server) move(id, direction string) (mazelib.Reply, int) {
+	sess, found := s.sessions.use(id)
+	if !found {
+		return errorReply(mazelib.ErrNoSession)
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	r, code := sess.move(direction)
+	if r.Victory {
+		s.logVictory(sess)
+	}
+
+	if code == http.StatusOK && s.logger.Enabled(log.LevelDebug) {
+		s.printMaze(sess)
+	}
+
+	return r, code
+}
+
+// logVictory logs that Icarus has reached the treasure of the maze of sess.
+// The caller must hold sess.mu.
+func (s *server) logVictory(sess *session) {
+	s.logger.With("session", sess.id, "step", sess.maze.StepsTaken).Infof("Victory achieved in %d steps", sess.maze.StepsTaken)
+}
+
+// moveBatch moves Icarus along directions in the session identified by id.
+// The moves are executed atomically up to the first wall, victory or error.
+// It returns the surveys of every step along with the final status
+// and the corresponding HTTP status code.
+func (s *server) moveBatch(id string, directions []string) (mazelib.BatchReply, int) {
+	sess, found := s.sessions.use(id)
+	if !found {
+		r, code := errorReply(mazelib.ErrNoSession)
+		return mazelib.BatchReply{Reply: r}, code
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	br := mazelib.BatchReply{Surveys: make([]mazelib.Survey, 0, len(directions))}
+	code := http.StatusOK
+	for _, dir := range directions {
+		br.Reply, code = sess.move(dir)
+		if code != http.StatusOK {
+			break
+		}
+		br.Surveys = append(br.Surveys, br.Reply.Survey)
+		if br.Reply.Victory {
+			s.logVictory(sess)
+		}
+		if br.Reply.Victory || br.Reply.Error {
+			break
 		}
 	}
 
-	r.Survey = s
+	if sess.maze != nil && s.logger.Enabled(log.LevelDebug) {
+		s.printMaze(sess)
+	}
 
-	c.JSON(http.StatusOK, r)
+	return br, code
+}
 
-	if viper.GetBool("debug") {
-		mazelib.PrintMaze(currentMaze)
+// end ends the session identified by id and flushes its results.
+// It returns the reply to Icarus along with the corresponding HTTP status code.
+func (s *server) end(id string) (mazelib.Reply, int) {
+	sess, found := s.sessions.remove(id)
+	if !found {
+		return errorReply(mazelib.ErrNoSession)
 	}
+
+	s.flush(sess)
+	return mazelib.Reply{Session: sess.id}, http.StatusOK
 }
 
-func initializeMaze(x, y int) {
-	currentMaze = createMaze(x, y)
+// errorReply returns the reply describing err along with the corresponding HTTP status code.
+func errorReply(err error) (mazelib.Reply, int) {
+	r := mazelib.ErrorReply(err)
+	return r, r.Code.HTTPStatus()
 }
 
-// Print to the terminal the average steps to solution for the current session
-func printResults() {
-	fmt.Printf("Labyrinth solved %d times with an avg of %d steps\n", len(scores), mazelib.AvgScores(scores))
+// exec executes cmd streamed over a connection which plays the session identified by *id.
+// It updates *id as the session starts and ends, and returns the reply to cmd.
+func (s *server) exec(id *string, cmd mazelib.Command) mazelib.Reply {
+	var r mazelib.Reply
+	switch cmd.Action {
+	case mazelib.ActionAwake:
+		r, _ = s.awake(*id, cmd.Solver)
+		if !r.Error {
+			*id = r.Session
+		}
+	case mazelib.ActionMove:
+		r, _ = s.move(*id, cmd.Direction)
+	case mazelib.ActionDone:
+		r, _ = s.end(*id)
+		*id = ""
+	default:
+		r = mazelib.Reply{Error: true, Message: fmt.Sprintf("unknown action %q", cmd.Action)}
+	}
+	return r
 }
 
 // GetRoom returns a room from the maze
 func (m *Maze) GetRoom(x, y int) (*mazelib.Room, error) {
 	if x < 0 || y < 0 || x >= m.Width() || y >= m.Height() {
-		return &mazelib.Room{}, errors.New("room outside of maze boundaries")
+		return &mazelib.Room{}, mazelib.ErrOutOfBounds
 	}
 
 	return &m.rooms[y][x], nil
@@ -210,10 +647,18 @@ func (m *Maze) SetStartPoint(x, y int) error {
 	}
 
 	r.Start = true
+	m.start = mazelib.Coordinate{x, y}
 	m.icarus = mazelib.Coordinate{x, y}
+	m.visit()
 	return nil
 }
 
+// visit marks the room Icarus is in as visited.
+func (m *Maze) visit() {
+	m.rooms[m.icarus.Y][m.icarus.X].Visited = true
+	m.visits[m.icarus.Y][m.icarus.X]++
+}
+
 // SetTreasure sets the location of the treasure for a given maze
 func (m *Maze) SetTreasure(x, y int) error {
 	r, err := m.GetRoom(x, y)
@@ -235,7 +680,6 @@ func (m *Maze) SetTreasure(x, y int) error {
 // It will return ErrVictory if Icarus is at the treasure.
 func (m *Maze) LookAround() (mazelib.Survey, error) {
 	if m.end.X == m.icarus.X && m.end.Y == m.icarus.Y {
-		fmt.Printf("Victory achieved in %d steps \n", m.StepsTaken)
 		return mazelib.Survey{}, mazelib.ErrVictory
 	}
 
@@ -247,7 +691,7 @@ func (m *Maze) LookAround() (mazelib.Survey, error) {
 func (m *Maze) Discover(x, y int) (mazelib.Survey, error) {
 	r, err := m.GetRoom(x, y)
 	if err != nil {
-		return mazelib.Survey{}, nil
+		return mazelib.Survey{}, err
 	}
 	return r.Walls, nil
 }
@@ -260,7 +704,7 @@ func (m *Maze) MoveLeft() error {
 		return e
 	}
 	if s.Left {
-		return errors.New("Can't walk through walls")
+		return mazelib.ErrWall
 	}
 
 	x, y := m.Icarus()
@@ -270,6 +714,7 @@ func (m *Maze) MoveLeft() error {
 
 	m.icarus = mazelib.Coordinate{x - 1, y}
 	m.StepsTaken++
+	m.visit()
 	return nil
 }
 
@@ -281,7 +726,7 @@ func (m *Maze) MoveRight() error {
 		return e
 	}
 	if s.Right {
-		return errors.New("Can't walk through walls")
+		return mazelib.ErrWall
 	}
 
 	x, y := m.Icarus()
@@ -291,6 +736,7 @@ func (m *Maze) MoveRight() error {
 
 	m.icarus = mazelib.Coordinate{x + 1, y}
 	m.StepsTaken++
+	m.visit()
 	return nil
 }
 
@@ -302,7 +748,7 @@ func (m *Maze) MoveUp() error {
 		return e
 	}
 	if s.Top {
-		return errors.New("Can't walk through walls")
+		return mazelib.ErrWall
 	}
 
 	x, y := m.Icarus()
@@ -312,6 +758,7 @@ func (m *Maze) MoveUp() error {
 
 	m.icarus = mazelib.Coordinate{x, y - 1}
 	m.StepsTaken++
+	m.visit()
 	return nil
 }
 
@@ -323,7 +770,7 @@ func (m *Maze) MoveDown() error {
 		return e
 	}
 	if s.Bottom {
-		return errors.New("Can't walk through walls")
+		return mazelib.ErrWall
 	}
 
 	x, y := m.Icarus()
@@ -333,6 +780,7 @@ func (m *Maze) MoveDown() error {
 
 	m.icarus = mazelib.Coordinate{x, y + 1}
 	m.StepsTaken++
+	m.visit()
 	return nil
 }
 
@@ -363,9 +811,10 @@ func (m *Maze) DeadEnds() []*mazelib.Room {
 
 // Braid rearranges the Maze to "braid" one, that is, a maze without dead ends.
 // p is the probability for the occurrence of braids. If p <= 0.0, it does nothing.
-func (m *Maze) Braid(p float64) {
-	for _, room := range mazelib.Shuffle(m.AllRooms()) {
-		if len(room.Links()) != 1 || rand.Float64() > p {
+// The rooms are chosen by using rnd.
+func (m *Maze) Braid(rnd *rand.Rand, p float64) {
+	for _, room := range mazelib.Shuffle(rnd, m.AllRooms()) {
+		if len(room.Links()) != 1 || rnd.Float64() > p {
 			continue
 		}
 
@@ -387,7 +836,7 @@ func (m *Maze) Braid(p float64) {
 			best = nbs
 		}
 
-		room.Link(mazelib.Random(best))
+		room.Link(mazelib.Random(rnd, best))
 	}
 }
 
@@ -397,8 +846,10 @@ func emptyMaze(xSize, ySize int) *Maze {
 	z := Maze{}
 
 	z.rooms = make([][]mazelib.Room, ySize)
+	z.visits = make([][]int, ySize)
 	for y := 0; y < ySize; y++ {
 		z.rooms[y] = make([]mazelib.Room, xSize)
+		z.visits[y] = make([]int, xSize)
 		for x := 0; x < xSize; x++ {
 			z.rooms[y][x] = mazelib.NewRoom()
 		}
@@ -448,18 +899,21 @@ func fullMaze(xSize, ySize int) *Maze {
 	return z
 }
 
-func createMaze(xSize, ySize int) *Maze {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+// createMaze creates a maze generated from seed.
+// The same seed always creates the same maze given the same size and --braid.
+func createMaze(xSize, ySize int, seed int64) *Maze {
+	r := rand.New(rand.NewSource(seed))
 
-	z := recursiveBacktracker(xSize, ySize)
+	z := recursiveBacktracker(r, xSize, ySize)
+	z.seed, z.algorithm = seed, algorithmBacktracker
 
-	z.Braid(viper.GetFloat64("braid"))
+	z.Braid(r, viper.GetFloat64("braid"))
 
 	// set the starting point and goal randomly
 	w, h := z.Width(), z.Height()
 	sx, sy := r.Intn(w), r.Intn(h)
+	// the start and the treasure are always in the maze
 	if e := z.SetStartPoint(sx, sy); e != nil {
-		log.Errorf("error setting start point: %v\n", e)
 		return emptyMaze(xSize, ySize)
 	}
 
@@ -469,20 +923,66 @@ func createMaze(xSize, ySize int) *Maze {
 		tx, ty = r.Intn(w), r.Intn(h)
 	}
 	if e := z.SetTreasure(tx, ty); e != nil {
-		log.Errorf("error setting treasure: %v\n", e)
 		return emptyMaze(xSize, ySize)
 	}
 
 	return z
 }
 
-// recursiveBacktracker creates a maze by using recursive backtracker algorithm.
-func recursiveBacktracker(xSize, ySize int) *Maze {
+// mazeFromData creates a maze from d, which must be valid.
+func mazeFromData(d mazelib.MazeData) *Maze {
+	z := fullMaze(d.Width, d.Height)
+	z.seed, z.algorithm = d.Seed, d.Algorithm
+
+	for y, row := range d.Walls {
+		for x, walls := range row {
+			if x+1 < d.Width && !walls.Right {
+				z.rooms[y][x].Link(&z.rooms[y][x+1])
+			}
+			if y+1 < d.Height && !walls.Bottom {
+				z.rooms[y][x].Link(&z.rooms[y+1][x])
+			}
+		}
+	}
+
+	_ = z.SetStartPoint(d.Start.X, d.Start.Y)
+	_ = z.SetTreasure(d.Treasure.X, d.Treasure.Y)
+	return z
+}
+
+// data returns the serializable data of m.
+func (m *Maze) data() mazelib.MazeData {
+	d := mazelib.Snapshot(m)
+	d.Algorithm, d.Seed = m.algorithm, m.seed
+	return d
+}
+
+// loadMazeFile loads the maze saved in JSON at path.
+// It returns nil if path is empty.
+func loadMazeFile(path string) (*mazelib.MazeData, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := mazelib.UnmarshalMaze(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &d, nil
+}
+
+// recursiveBacktracker creates a maze by using recursive backtracker algorithm
+// with the random choices made by rnd.
+func recursiveBacktracker(rnd *rand.Rand, xSize, ySize int) *Maze {
 	z := fullMaze(xSize, ySize)
 
 	// pick a starting Room randomly
 	w, h := z.Width(), z.Height()
-	start, err := z.GetRoom(rand.Intn(w), rand.Intn(h))
+	start, err := z.GetRoom(rnd.Intn(w), rnd.Intn(h))
 	if err != nil {
 		start, _ = z.GetRoom(0, 0)
 	}
@@ -504,7 +1004,7 @@ func recursiveBacktracker(xSize, ySize int) *Maze {
 			continue
 		}
 
-		nb := mazelib.Random(nbs)
+		nb := mazelib.Random(rnd, nbs)
 		current.Link(nb)
 		stack = append(stack, nb)
 	}
@@ -1,15 +1,63 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/skatsuta/labyrinth/history"
+	"github.com/skatsuta/labyrinth/log"
 	"github.com/skatsuta/labyrinth/mazelib"
 )
 
 func TestPrintMaze(t *testing.T) {
 	x, y := 15, 10
-	z := createMaze(x, y)
-	mazelib.PrintMaze(z)
+	z := createMaze(x, y, 1)
+
+	var buf bytes.Buffer
+	if err := (mazelib.Renderer{}).Render(&buf, z); err != nil {
+		t.Fatal(err)
+	}
+	// Icarus is on the start of a new maze
+	if want := string(mazelib.MarshalPrint(z.data())); buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	d := z.data()
+	s := newServer(multiSink{})
+	s.template, s.out = &d, &buf
+	buf.Reset()
+	r, _ := s.awake("", "")
+	if buf.String() != string(mazelib.MarshalPrint(d)) {
+		t.Errorf("the awakening should print the maze: got\n%s", buf.String())
+	}
+
+	// at the debug level, every move prints the maze with the path of Icarus
+	s.logger = log.New(io.Discard, log.Options{Level: log.LevelDebug})
+	s.renderer.Style = mazelib.StyleASCII
+	there, back := "up", "down"
+	switch {
+	case !r.Survey.Right:
+		there, back = "right", "left"
+	case !r.Survey.Bottom:
+		there, back = "down", "up"
+	case !r.Survey.Left:
+		there, back = "left", "right"
+	}
+	s.move(r.Session, there)
+	buf.Reset()
+	s.move(r.Session, back)
+	if got := strings.Count(buf.String(), " . "); got != 1 {
+		t.Errorf("got %d rooms on the path; want 1:\n%s", got, buf.String())
+	}
 }
 
 func createUshapedMaze() *Maze {
@@ -20,6 +68,74 @@ func createUshapedMaze() *Maze {
 	return m
 }
 
+func TestCreateMazeIsReproducible(t *testing.T) {
+	walls := func(m *Maze) [][]mazelib.Survey {
+		w := make([][]mazelib.Survey, m.Height())
+		for y := range w {
+			w[y] = make([]mazelib.Survey, m.Width())
+			for x := range w[y] {
+				w[y][x] = m.rooms[y][x].Walls
+			}
+		}
+		return w
+	}
+
+	for _, seed := range []int64{1, 42, -7} {
+		m1, m2 := createMaze(15, 10, seed), createMaze(15, 10, seed)
+		if !reflect.DeepEqual(walls(m1), walls(m2)) || m1.start != m2.start || m1.end != m2.end {
+			t.Errorf("seed %d: mazes from the same seed differ", seed)
+		}
+		if m1.seed != seed {
+			t.Errorf("got seed %d; want %d", m1.seed, seed)
+		}
+	}
+
+	if m1, m2 := createMaze(15, 10, 1), createMaze(15, 10, 2); reflect.DeepEqual(walls(m1), walls(m2)) {
+		t.Error("mazes from different seeds should differ")
+	}
+}
+
+func TestMazeFromData(t *testing.T) {
+	m := createMaze(15, 10, 42)
+	d := m.data()
+
+	got := mazeFromData(d)
+	if !reflect.DeepEqual(got.data(), d) {
+		t.Errorf("got %+v; want %+v", got.data(), d)
+	}
+	if len(got.DeadEnds()) != len(m.DeadEnds()) {
+		t.Errorf("got %d dead ends; want %d", len(got.DeadEnds()), len(m.DeadEnds()))
+	}
+	if got.icarus != m.icarus || got.end != m.end {
+		t.Errorf("got icarus at %v and treasure at %v; want %v and %v", got.icarus, got.end, m.icarus, m.end)
+	}
+}
+
+func TestSeeder(t *testing.T) {
+	s := seeder{base: 10}
+	for want := int64(10); want < 13; want++ {
+		if got := s.next(); got != want {
+			t.Errorf("got seed %d; want %d", got, want)
+		}
+	}
+}
+
+func TestSeederUnseeded(t *testing.T) {
+	var s seeder
+	seen := make(map[int64]bool)
+	for i := 0; i < 100; i++ {
+		seed := s.next()
+		if seed <= 0 || seen[seed] {
+			t.Fatalf("got seed %d after %v; want distinct positive seeds", seed, seen)
+		}
+		seen[seed] = true
+	}
+
+	if a, b := (&seeder{}).next(), (&seeder{}).next(); a == b {
+		t.Errorf("unseeded seeders should start at random seeds: both got %d", a)
+	}
+}
+
 func TestBraid(t *testing.T) {
 	tests := []struct {
 		m    *Maze
@@ -32,7 +148,7 @@ func TestBraid(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		tt.m.Braid(tt.p)
+		tt.m.Braid(rand.New(rand.NewSource(1)), tt.p)
 		got := len(tt.m.DeadEnds())
 		if got != tt.want {
 			t.Errorf("# of dead ends by p = %.1f should be %d, but got %d", tt.p, tt.want, got)
@@ -98,3 +214,321 @@ func TestDeadEnds(t *testing.T) {
 		}
 	}
 }
+
+// recordSink is a ResultsSink and TraceSink which records flushed results and traces.
+type recordSink struct {
+	results []Results
+	traces  []mazelib.Trace
+}
+
+func (r *recordSink) Flush(res Results) error {
+	r.results = append(r.results, res)
+	return nil
+}
+
+func (r *recordSink) Trace(session string, n int, t mazelib.Trace) error {
+	r.traces = append(r.traces, t)
+	return nil
+}
+
+func serve(t *testing.T, h http.Handler, path string) (int, mazelib.Reply) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+	var r mazelib.Reply
+	if err := json.Unmarshal(w.Body.Bytes(), &r); err != nil {
+		t.Fatalf("GET %s: invalid reply %q: %v", path, w.Body.String(), err)
+	}
+	return w.Code, r
+}
+
+func TestEndOnlyEndsCallingSession(t *testing.T) {
+	sink := &recordSink{}
+	s := newServer(sink)
+	h := s.handler()
+
+	_, r1 := serve(t, h, "/awake")
+	_, r2 := serve(t, h, "/awake")
+	if r1.Session == "" || r1.Session == r2.Session {
+		t.Fatalf("each awakening should start a distinct session: got %q and %q", r1.Session, r2.Session)
+	}
+
+	if code, _ := serve(t, h, "/done?session="+r1.Session); code != http.StatusOK {
+		t.Errorf("/done: got status %d; want %d", code, http.StatusOK)
+	}
+	if len(sink.results) != 1 || sink.results[0].Session != r1.Session {
+		t.Errorf("results of %q should be flushed once, but got %v", r1.Session, sink.results)
+	}
+
+	if code, _ := serve(t, h, "/done?session="+r1.Session); code != http.StatusNotFound {
+		t.Errorf("/done twice: got status %d; want %d", code, http.StatusNotFound)
+	}
+	if _, found := s.sessions.get(r2.Session); !found {
+		t.Errorf("session %q should be still active", r2.Session)
+	}
+
+	s.flushAll()
+	if len(sink.results) != 2 {
+		t.Errorf("got %d results after flushing all; want 2", len(sink.results))
+	}
+}
+
+func TestExpireIdleSessions(t *testing.T) {
+	sink := &recordSink{}
+	s := newServer(sink)
+	s.logger = log.New(io.Discard, log.Options{})
+	h := s.handler()
+
+	_, idle := serve(t, h, "/awake")
+	_, busy := serve(t, h, "/awake")
+	for _, id := range []string{idle.Session, busy.Session} {
+		sess, _ := s.sessions.get(id)
+		sess.active = time.Now().Add(-time.Hour)
+	}
+	// spectating does not keep a session alive, but a request of Icarus does
+	serve(t, h, "/spectate?session="+idle.Session)
+	serve(t, h, "/move/up?session="+busy.Session)
+
+	s.expire(time.Now().Add(-time.Minute))
+	if len(sink.results) != 1 || sink.results[0].Session != idle.Session {
+		t.Fatalf("got results %+v; want those of the idle session %q", sink.results, idle.Session)
+	}
+	if runs := sink.results[0].Runs; len(runs) != 1 || runs[0].Outcome != history.Abandoned {
+		t.Errorf("got runs %+v; want an abandoned run", runs)
+	}
+	if _, found := s.sessions.get(idle.Session); found {
+		t.Errorf("the idle session %q should be removed", idle.Session)
+	}
+	if _, found := s.sessions.get(busy.Session); !found {
+		t.Errorf("the busy session %q should be still active", busy.Session)
+	}
+}
+
+func TestMoveBatch(t *testing.T) {
+	tests := []struct {
+		dirs        []string
+		wantCode    int
+		wantSteps   int
+		wantVictory bool
+	}{
+		{[]string{"right", "down", "left", "up"}, http.StatusOK, 3, true},
+		{[]string{"right", "down"}, http.StatusOK, 2, false},
+		{[]string{"right", "up"}, http.StatusConflict, 1, false},
+		{[]string{"down"}, http.StatusConflict, 0, false},
+	}
+
+	for _, tt := range tests {
+		s := newServer(&recordSink{})
+		sess, _ := s.sessions.create()
+		sess.maze = createUshapedMaze()
+		_ = sess.maze.SetStartPoint(0, 0)
+		_ = sess.maze.SetTreasure(0, 1)
+
+		r, code := s.moveBatch(sess.id, tt.dirs)
+		if code != tt.wantCode {
+			t.Errorf("%v: got status %d; want %d", tt.dirs, code, tt.wantCode)
+		}
+		if len(r.Surveys) != tt.wantSteps {
+			t.Errorf("%v: got %d surveys; want %d", tt.dirs, len(r.Surveys), tt.wantSteps)
+		}
+		if r.Victory != tt.wantVictory {
+			t.Errorf("%v: got victory %t; want %t", tt.dirs, r.Victory, tt.wantVictory)
+		}
+	}
+}
+
+func TestRunHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	s := newServer(multiSink{&recordSink{}, historySink{path: path}})
+	h := s.handler()
+
+	_, r := serve(t, h, "/awake?solver=minos")
+	sess, _ := s.sessions.get(r.Session)
+	sess.maze = createUshapedMaze()
+	_ = sess.maze.SetStartPoint(0, 0)
+	_ = sess.maze.SetTreasure(0, 1)
+	for _, dir := range []string{"right", "down", "left"} {
+		serve(t, h, "/move/"+dir+"?session="+r.Session)
+	}
+
+	// a solved maze is not abandoned by the next one, but the next one is by the end
+	serve(t, h, "/awake?session="+r.Session)
+	serve(t, h, "/done?session="+r.Session)
+
+	st, err := history.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	runs, err := st.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		steps   int
+		outcome history.Outcome
+	}{
+		{3, history.Victory},
+		{0, history.Abandoned},
+	}
+	if len(runs) != len(want) {
+		t.Fatalf("got %d runs; want %d", len(runs), len(want))
+	}
+	for i, w := range want {
+		if runs[i].Solver != "minos" || runs[i].Steps != w.steps || runs[i].Outcome != w.outcome {
+			t.Errorf("run %d: got %s %d %s; want minos %d %s", i, runs[i].Solver, runs[i].Steps, runs[i].Outcome, w.steps, w.outcome)
+		}
+	}
+	if runs[1].Algorithm != algorithmBacktracker {
+		t.Errorf("got algorithm %q; want %q", runs[1].Algorithm, algorithmBacktracker)
+	}
+}
+
+func TestMoveLog(t *testing.T) {
+	sink := &recordSink{}
+	s := newServer(multiSink{sink, gifSink{dir: t.TempDir()}})
+	h := s.handler()
+
+	_, r := serve(t, h, "/awake")
+	sess, _ := s.sessions.get(r.Session)
+	sess.maze = createUshapedMaze()
+	_ = sess.maze.SetStartPoint(0, 0)
+	_ = sess.maze.SetTreasure(0, 1)
+	for _, dir := range []string{"down", "right", "sideways", "down", "left"} {
+		serve(t, h, "/move/"+dir+"?session="+r.Session)
+	}
+	serve(t, h, "/done?session="+r.Session)
+
+	replays := sink.results[0].Replays
+	if len(replays) != 1 {
+		t.Fatalf("got %d replays; want 1", len(replays))
+	}
+	want := []mazelib.Step{
+		{Direction: mazelib.S, To: mazelib.Coordinate{X: 0, Y: 0}, Blocked: true},
+		{Direction: mazelib.E, To: mazelib.Coordinate{X: 1, Y: 0}},
+		{Direction: mazelib.S, To: mazelib.Coordinate{X: 1, Y: 1}},
+		{Direction: mazelib.W, To: mazelib.Coordinate{X: 0, Y: 1}},
+	}
+	if got := replays[0].Steps; !reflect.DeepEqual(got, want) {
+		t.Errorf("got steps %+v; want %+v", got, want)
+	}
+	if got := replays[0].Maze.Start; got != (mazelib.Coordinate{X: 0, Y: 0}) {
+		t.Errorf("got start %v; want (0, 0)", got)
+	}
+}
+
+func TestMoveLogUnsaved(t *testing.T) {
+	sink := &recordSink{}
+	s := newServer(sink)
+	h := s.handler()
+
+	_, r := serve(t, h, "/awake")
+	serve(t, h, "/move/up?session="+r.Session)
+	serve(t, h, "/awake?session="+r.Session)
+	serve(t, h, "/done?session="+r.Session)
+
+	if got := sink.results[0]; len(got.Runs) != 2 || len(got.Replays) != 0 {
+		t.Errorf("got %d runs and %d replays; want 2 runs and no replays without GIFs", len(got.Runs), len(got.Replays))
+	}
+}
+
+func TestMoveDirectionStatus(t *testing.T) {
+	s := newServer(&recordSink{})
+	h := s.handler()
+
+	_, r := serve(t, h, "/awake")
+	sess, _ := s.sessions.get(r.Session)
+	sess.maze = createUshapedMaze()
+	_ = sess.maze.SetStartPoint(0, 0)
+	_ = sess.maze.SetTreasure(0, 1)
+
+	tests := []struct {
+		path     string
+		wantCode mazelib.ErrorCode
+		wantHTTP int
+	}{
+		{"/move/up?session=nobody", mazelib.CodeNoSession, http.StatusNotFound},
+		{"/move/sideways?session=" + r.Session, mazelib.CodeBadDirection, http.StatusBadRequest},
+		{"/move/down?session=" + r.Session, mazelib.CodeWall, http.StatusConflict},
+		{"/move/right?session=" + r.Session, "", http.StatusOK},
+		{"/move/down?session=" + r.Session, "", http.StatusOK},
+		{"/move/left?session=" + r.Session, "", http.StatusOK},
+		{"/move/right?session=" + r.Session, mazelib.CodeFinished, http.StatusGone},
+	}
+
+	for _, tt := range tests {
+		code, got := serve(t, h, tt.path)
+		if code != tt.wantHTTP || got.Code != tt.wantCode {
+			t.Errorf("GET %s: got %d %q; want %d %q", tt.path, code, got.Code, tt.wantHTTP, tt.wantCode)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	s := newServer(&recordSink{})
+	s.token = "secret"
+	h := s.handler()
+
+	tests := []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"secret", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/awake", nil)
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("Authorization %q: got status %d; want %d", tt.auth, w.Code, tt.want)
+		}
+	}
+
+	// health checks need no token
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("/healthz: got status %d; want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	s := newServer(multiSink{})
+	s.logger = log.New(&buf, log.Options{})
+
+	serve(t, s.handler(), "/move/up?session=nope")
+	want := "[INFO] GET /move/up method=GET path=/move/up status=404 latency="
+	if !strings.HasPrefix(buf.String(), want) || !strings.Contains(buf.String(), " session=nope\n") {
+		t.Errorf("got %q; want an access log of the request", buf.String())
+	}
+}
+
+func TestVictoryLog(t *testing.T) {
+	m := createUshapedMaze()
+	_ = m.SetStartPoint(0, 0)
+	_ = m.SetTreasure(0, 1)
+	d := m.data()
+
+	var buf bytes.Buffer
+	s := newServer(multiSink{})
+	s.template, s.out = &d, io.Discard
+	s.logger = log.New(&buf, log.Options{JSON: true})
+
+	r, _ := s.awake("", "")
+	s.move(r.Session, "right")
+	s.moveBatch(r.Session, []string{"down", "left"})
+
+	want := `"level":"info","msg":"Victory achieved in 3 steps","session":"` + r.Session + `","step":3}`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got log %q; want a record containing %q", buf.String(), want)
+	}
+}
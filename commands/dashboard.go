@@ -0,0 +1,124 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+// dashboardHTML is the page of the dashboard. It loads nothing but the events
+// of the server, so it works offline.
+//
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// Intervals of the event stream of the dashboard.
+const (
+	// eventInterval is how often the sessions are checked for changes.
+	eventInterval = 200 * time.Millisecond
+	// heartbeatInterval is how often a comment is sent while nothing changes
+	// so that proxies keep the stream open.
+	heartbeatInterval = 15 * time.Second
+)
+
+// Dashboard returns the API response to the /dashboard address:
+// a page which draws the mazes of all the active sessions and Icarus in them live.
+func (s *server) Dashboard(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", dashboardHTML)
+}
+
+// Events returns the API response to the /events address: a stream of
+// Server-Sent Events, each of which is a "sessions" event with the JSON
+// array of the mazelib.Sighting of every active session, revealing whole mazes.
+// An event is sent when the stream opens and whenever the sessions change.
+func (s *server) Events(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	ticker := time.NewTicker(eventInterval)
+	defer ticker.Stop()
+
+	var (
+		last []byte
+		idle time.Duration
+	)
+	for {
+		b, err := json.Marshal(s.sightings())
+		if err != nil {
+			s.logger.Errorf("error encoding sessions: %v\n", err)
+			return
+		}
+		switch {
+		case !bytes.Equal(b, last):
+			_, err = fmt.Fprintf(c.Writer, "event: sessions\ndata: %s\n\n", b)
+			last, idle = b, 0
+		case idle >= heartbeatInterval:
+			_, err = fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			idle = 0
+		}
+		if err != nil {
+			return
+		}
+		c.Writer.Flush()
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			idle += eventInterval
+		}
+	}
+}
+
+// sightings returns the whole mazes of all the active sessions and Icarus in them.
+func (s *server) sightings() []mazelib.Sighting {
+	list := s.sessions.all()
+	sgs := make([]mazelib.Sighting, 0, len(list))
+	for _, sess := range list {
+		sess.mu.Lock()
+		if sess.maze != nil {
+			sgs = append(sgs, sess.sighting(true))
+		}
+		sess.mu.Unlock()
+	}
+	return sgs
+}
+
+// authorizeStream is a middleware like authorize which also accepts the token
+// in the token parameter, since browsers cannot set headers on event streams.
+func (s *server) authorizeStream(c *gin.Context) {
+	if tok := c.Query("token"); tok != "" && s.authorized("Bearer "+tok) {
+		return
+	}
+	s.authorize(c)
+}
+
+// stopStreams ends the event streams so that the server can shut down.
+func (s *server) stopStreams() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+func TestDashboard(t *testing.T) {
+	s := newServer(&recordSink{})
+	s.token = "secret"
+	h := s.handler()
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/dashboard", http.StatusUnauthorized},
+		{"/dashboard?token=nope", http.StatusUnauthorized},
+		{"/dashboard?token=secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: got status %d; want %d", tt.path, w.Code, tt.want)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+
+		body := w.Body.String()
+		if !strings.Contains(body, "EventSource") {
+			t.Error("the dashboard should listen to the events")
+		}
+		// everything is embedded so that it works offline
+		for _, ext := range []string{"http://", "https://", "<script src", "<link"} {
+			if strings.Contains(body, ext) {
+				t.Errorf("the dashboard should not load %q", ext)
+			}
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	s := newServer(&recordSink{})
+	s.out = io.Discard
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	r, _ := s.awake("", "tester")
+	sess, _ := s.sessions.get(r.Session)
+	sess.mu.Lock()
+	sess.maze = createUshapedMaze()
+	_ = sess.maze.SetStartPoint(0, 0)
+	_ = sess.maze.SetTreasure(0, 1)
+	sess.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("got Content-Type %q", ct)
+	}
+
+	events := bufio.NewScanner(resp.Body)
+	next := func() []mazelib.Sighting {
+		t.Helper()
+		for events.Scan() {
+			data, ok := strings.CutPrefix(events.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var sgs []mazelib.Sighting
+			if err := json.Unmarshal([]byte(data), &sgs); err != nil {
+				t.Fatalf("invalid event %q: %v", data, err)
+			}
+			return sgs
+		}
+		t.Fatalf("the stream ended: %v", events.Err())
+		return nil
+	}
+
+	sgs := next()
+	if len(sgs) != 1 || sgs[0].Session != r.Session || sgs[0].Solver != "tester" {
+		t.Fatalf("got %+v", sgs)
+	}
+	// the whole maze is revealed
+	if room := sgs[0].Rooms[1][0]; room == nil || !room.Treasure || room.Visits != 0 {
+		t.Errorf("got treasure room %+v", room)
+	}
+
+	s.move(r.Session, "right")
+	if sgs := next(); sgs[0].Steps != 1 || sgs[0].Icarus != (mazelib.Coordinate{X: 1, Y: 0}) {
+		t.Errorf("got %+v after a move", sgs[0])
+	}
+
+	s.end(r.Session)
+	if sgs := next(); len(sgs) != 0 {
+		t.Errorf("got %d sessions after the end; want 0", len(sgs))
+	}
+
+	// the stream ends when the server shuts down
+	s.stopStreams()
+	for events.Scan() {
+	}
+	if err := events.Err(); err != nil {
+		t.Errorf("the stream should end cleanly: %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+// event is what Icarus is about to do in a step, on which the debugger can break.
+type event string
+
+// Events of the solver.
+const (
+	eventMove      event = "move"
+	eventJunction  event = "junction"
+	eventBacktrack event = "backtrack"
+	eventVictory   event = "victory"
+)
+
+// breakEvents are the events the debugger can break on.
+var breakEvents = []event{eventJunction, eventBacktrack, eventVictory}
+
+// frame is the state of the solver at a step.
+type frame struct {
+	count int
+	// pos is where Icarus is relative to the start, with x growing right and y growing down.
+	pos    mazelib.Coordinate
+	event  event
+	survey mazelib.Survey
+	// choices are the directions the solver chooses next from.
+	choices []mazelib.Direction
+	next    mazelib.Direction
+	stack   []record
+}
+
+// debuggerHelp describes the commands of the debugger.
+const debuggerHelp = `Commands:
+  step [n]         run n steps (default 1)
+  continue [n]     run until step n, or until a breakpoint
+  break x,y        break when Icarus is at x,y relative to the start
+  break event      break on an event: junction, backtrack or victory
+  delete [bp]      delete a breakpoint, or all of them
+  breakpoints      list the breakpoints
+  print            print the current step
+  stack            print the records on the stack of the solver
+  quit             give up the current laybrinth
+  help             print this help
+An empty line repeats the last command. Commands can be shortened to
+their first letter, and bl to breakpoints.
+`
+
+// debugger is a REPL which stops the solver at steps and lets the user inspect it.
+type debugger struct {
+	in  *bufio.Scanner
+	out io.Writer
+	// left is how many steps to run before stopping, if positive.
+	left int
+	// until is the step to stop at, if positive.
+	until  int
+	coords map[mazelib.Coordinate]bool
+	events map[event]bool
+	last   string
+	// detached is set once the input ends, after which the solver runs without stopping.
+	detached bool
+}
+
+// newDebugger returns a debugger reading commands from in and writing to out,
+// which stops at the first step.
+func newDebugger(in io.Reader, out io.Writer) *debugger {
+	return &debugger{
+		in:     bufio.NewScanner(in),
+		out:    out,
+		left:   1,
+		coords: make(map[mazelib.Coordinate]bool),
+		events: make(map[event]bool),
+		last:   "step",
+	}
+}
+
+// shouldBreak reports whether the debugger stops at f.
+func (d *debugger) shouldBreak(f frame) bool {
+	if d.detached {
+		return false
+	}
+	if d.left > 0 {
+		d.left--
+		if d.left == 0 {
+			return true
+		}
+	}
+	if d.until > 0 && f.count >= d.until {
+		return true
+	}
+	return d.coords[f.pos] || d.events[f.event]
+}
+
+// pause stops the solver at f if it should break there, and reads commands
+// until one resumes the solver. It returns false if the user gives up.
+func (d *debugger) pause(f frame) bool {
+	if !d.shouldBreak(f) {
+		return true
+	}
+	d.left, d.until = 0, 0
+	d.print(f)
+
+	for {
+		fmt.Fprint(d.out, "(icarus) ")
+		if !d.in.Scan() {
+			fmt.Fprintln(d.out)
+			d.detached = true
+			return true
+		}
+
+		line := strings.TrimSpace(d.in.Text())
+		if line == "" {
+			line = d.last
+		}
+		d.last = line
+
+		fields := strings.Fields(line)
+		cmd, args := fields[0], fields[1:]
+		switch cmd {
+		case "s", "step":
+			n, err := countArg(args, 1)
+			if err != nil {
+				fmt.Fprintln(d.out, err)
+				continue
+			}
+			d.left = n
+			return true
+		case "c", "continue":
+			n, err := countArg(args, 0)
+			if err != nil {
+				fmt.Fprintln(d.out, err)
+				continue
+			}
+			if n > 0 && n <= f.count {
+				fmt.Fprintf(d.out, "step %d has passed\n", n)
+				continue
+			}
+			d.until = n
+			return true
+		case "b", "break":
+			if err := d.setBreakpoint(args, true); err != nil {
+				fmt.Fprintln(d.out, err)
+			}
+		case "d", "delete":
+			if len(args) == 0 {
+				d.coords = make(map[mazelib.Coordinate]bool)
+				d.events = make(map[event]bool)
+				continue
+			}
+			if err := d.setBreakpoint(args, false); err != nil {
+				fmt.Fprintln(d.out, err)
+			}
+		case "bl", "breakpoints":
+			d.printBreakpoints()
+		case "p", "print":
+			d.print(f)
+		case "stack":
+			printStack(d.out, f.stack)
+		case "q", "quit":
+			return false
+		case "h", "help":
+			fmt.Fprint(d.out, debuggerHelp)
+		default:
+			fmt.Fprintf(d.out, "unknown command %q; type help for the commands\n", cmd)
+		}
+	}
+}
+
+// countArg parses the optional count in args, which is def if args are empty.
+func countArg(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid count %q", args[0])
+	}
+	return n, nil
+}
+
+// setBreakpoint sets or deletes the breakpoint given in args.
+func (d *debugger) setBreakpoint(args []string, on bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("give a breakpoint as x,y or one of %v", breakEvents)
+	}
+
+	for _, e := range breakEvents {
+		if args[0] == string(e) {
+			if on {
+				d.events[e] = true
+			} else {
+				delete(d.events, e)
+			}
+			return nil
+		}
+	}
+
+	p, err := parseCoordinate(args[0])
+	if err != nil {
+		return err
+	}
+	if on {
+		d.coords[p] = true
+	} else {
+		delete(d.coords, p)
+	}
+	return nil
+}
+
+// parseCoordinate parses a coordinate written as x,y.
+func parseCoordinate(s string) (mazelib.Coordinate, error) {
+	xs, ys, ok := strings.Cut(s, ",")
+	x, xerr := strconv.Atoi(strings.TrimSpace(xs))
+	y, yerr := strconv.Atoi(strings.TrimSpace(ys))
+	if !ok || xerr != nil || yerr != nil {
+		return mazelib.Coordinate{}, fmt.Errorf("invalid breakpoint %q; want x,y or one of %v", s, breakEvents)
+	}
+	return mazelib.Coordinate{X: x, Y: y}, nil
+}
+
+// printBreakpoints prints the breakpoints set.
+func (d *debugger) printBreakpoints() {
+	if len(d.coords) == 0 && len(d.events) == 0 {
+		fmt.Fprintln(d.out, "no breakpoints")
+		return
+	}
+
+	coords := make([]mazelib.Coordinate, 0, len(d.coords))
+	for p := range d.coords {
+		coords = append(coords, p)
+	}
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].Y != coords[j].Y {
+			return coords[i].Y < coords[j].Y
+		}
+		return coords[i].X < coords[j].X
+	})
+	for _, p := range coords {
+		fmt.Fprintf(d.out, "  at %d,%d\n", p.X, p.Y)
+	}
+	for _, e := range breakEvents {
+		if d.events[e] {
+			fmt.Fprintf(d.out, "  on %s\n", e)
+		}
+	}
+}
+
+// print prints the step of f.
+func (d *debugger) print(f frame) {
+	fmt.Fprintf(d.out, "step %d at %d,%d: %s\n", f.count, f.pos.X, f.pos.Y, f.event)
+	if f.event == eventVictory {
+		return
+	}
+	fmt.Fprintf(d.out, "  walls: %s\n", wallNames(f.survey))
+	fmt.Fprintf(d.out, "  candidates: %s, next: %s\n", dirNames(f.choices), f.next)
+}
+
+// printStack prints the records on stk from the bottom,
+// along with where they are relative to the start.
+func printStack(w io.Writer, stk []record) {
+	var pos mazelib.Coordinate
+	for i, r := range stk {
+		fmt.Fprintf(w, "  #%d %d,%d walls: %s, moved: %s\n", i, pos.X, pos.Y, wallNames(r.survey), dirNames(r.dirs))
+		// the next record is the room Icarus has moved to last from this one
+		if len(r.dirs) > 0 {
+			pos = pos.Step(r.dirs[len(r.dirs)-1])
+		}
+	}
+}
+
+// wallNames returns the sides of the walls in s.
+func wallNames(s mazelib.Survey) string {
+	var names []string
+	for _, w := range []struct {
+		name string
+		wall bool
+	}{{"top", s.Top}, {"right", s.Right}, {"bottom", s.Bottom}, {"left", s.Left}} {
+		if w.wall {
+			names = append(names, w.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, " ")
+}
+
+// dirNames returns the names of dirs.
+func dirNames(dirs []mazelib.Direction) string {
+	if len(dirs) == 0 {
+		return "none"
+	}
+	names := make([]string, len(dirs))
+	for i, d := range dirs {
+		names[i] = d.String()
+	}
+	return strings.Join(names, " ")
+}
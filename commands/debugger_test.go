@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+// debugSolve lets Icarus solve the U-shaped maze under a debugger fed with commands,
+// and returns the output of the debugger and the results of the session.
+func debugSolve(t *testing.T, commands string) (string, Results) {
+	m := createUshapedMaze()
+	_ = m.SetStartPoint(0, 0)
+	_ = m.SetTreasure(0, 1)
+	d := m.data()
+
+	sink := &recordSink{}
+	s := newServer(sink)
+	s.template, s.out = &d, &bytes.Buffer{}
+	tr := &localTransport{s: s}
+
+	var out bytes.Buffer
+	solveMaze(context.Background(), tr, newDebugger(strings.NewReader(commands), &out))
+	if _, err := tr.Done(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.results) != 1 {
+		t.Fatalf("got %d results; want 1", len(sink.results))
+	}
+	return out.String(), sink.results[0]
+}
+
+func TestDebugger(t *testing.T) {
+	// an empty line steps by default
+	out, res := debugSolve(t, "\nbreak 1,1\nbreak junction\nbl\ncontinue\nstack\ndelete\ncontinue\n")
+
+	for _, want := range []string{
+		"step 1 at 0,0: move\n  walls: top bottom left\n  candidates: right, next: right\n",
+		"  at 1,1\n  on junction\n",
+		"step 2 at 1,0: move\n",
+		"step 3 at 1,1: move\n",
+		"  #0 0,0 walls: top bottom left, moved: right\n  #1 1,0 walls: top right, moved: left down\n  #2 1,1 walls: right bottom, moved: up\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("the output should contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "victory") {
+		t.Errorf("the debugger should not stop after deleting the breakpoints:\n%s", out)
+	}
+	if len(res.Scores) != 1 || res.Scores[0] != 3 {
+		t.Errorf("got scores %v; want [3]", res.Scores)
+	}
+}
+
+func TestDebuggerQuit(t *testing.T) {
+	out, res := debugSolve(t, "break nowhere\nstep 0\nquit\n")
+
+	for _, want := range []string{`invalid breakpoint "nowhere"`, `invalid count "0"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("the output should contain %q:\n%s", want, out)
+		}
+	}
+	if len(res.Scores) != 0 {
+		t.Errorf("quitting should give up the maze: got scores %v", res.Scores)
+	}
+}
+
+func TestDebuggerShouldBreak(t *testing.T) {
+	at := func(count, x, y int, e event) frame {
+		return frame{count: count, pos: mazelib.Coordinate{X: x, Y: y}, event: e}
+	}
+
+	tests := []struct {
+		name string
+		d    debugger
+		f    frame
+		want bool
+	}{
+		{"last step", debugger{left: 1}, at(5, 0, 0, eventMove), true},
+		{"steps left", debugger{left: 2}, at(5, 0, 0, eventMove), false},
+		{"until", debugger{until: 5}, at(5, 0, 0, eventMove), true},
+		{"before until", debugger{until: 6}, at(5, 0, 0, eventMove), false},
+		{"coordinate", debugger{coords: map[mazelib.Coordinate]bool{{X: 2, Y: -1}: true}}, at(5, 2, -1, eventMove), true},
+		{"event", debugger{events: map[event]bool{eventBacktrack: true}}, at(5, 0, 0, eventBacktrack), true},
+		{"other event", debugger{events: map[event]bool{eventBacktrack: true}}, at(5, 0, 0, eventJunction), false},
+		{"detached", debugger{left: 1, detached: true}, at(5, 0, 0, eventMove), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.d.shouldBreak(tt.f); got != tt.want {
+			t.Errorf("%s: got %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"os"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/spf13/cobra"
+)
+
+// Defining the generate command.
+// This will be called as 'laybrinth generate'
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a laybrinth and save it in JSON",
+	Long: `Generate creates a laybrinth in the same way as Daedalus does,
+and writes it in JSON to standard output or to the file given by --output.
+The file can be served by Daedalus later with --maze-file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := generateMaze()
+		if err != nil {
+			return err
+		}
+		b, err := mazelib.MarshalMaze(d)
+		if err != nil {
+			return err
+		}
+		b = append(b, '\n')
+
+		if out, _ := cmd.Flags().GetString("output"); out != "" {
+			return os.WriteFile(out, b, 0644)
+		}
+		_, err = os.Stdout.Write(b)
+		return err
+	},
+}
+
+func init() {
+	generateCmd.Flags().StringP("output", "o", "", "file to write the laybrinth to (default is standard output)")
+	RootCmd.AddCommand(generateCmd)
+}
@@ -0,0 +1,139 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/skatsuta/labyrinth/mazepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer serves the sessions of a server over gRPC.
+type grpcServer struct {
+	mazepb.UnimplementedDaedalusServer
+	s *server
+}
+
+// newGRPCServer returns a new grpc.Server which serves the sessions of s.
+// Requests must carry the bearer token of s if it has one.
+func newGRPCServer(s *server, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := s.authorizeGRPC(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authorizeGRPC(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+
+	gs := grpc.NewServer(opts...)
+	mazepb.RegisterDaedalusServer(gs, &grpcServer{s: s})
+	return gs
+}
+
+// authorizeGRPC checks the bearer token in the "authorization" metadata of ctx.
+func (s *server) authorizeGRPC(ctx context.Context) error {
+	var auth string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("authorization"); len(v) > 0 {
+			auth = v[0]
+		}
+	}
+	if s.authorized(auth) {
+		return nil
+	}
+	return status.Error(codes.Unauthenticated, mazelib.ErrUnauthorized.Error())
+}
+
+// Awake initializes a new maze and places Icarus in his awakening location.
+func (g *grpcServer) Awake(ctx context.Context, req *mazepb.AwakeRequest) (*mazepb.Reply, error) {
+	return grpcReply(g.s.awake(req.GetSession(), req.GetSolver()))
+}
+
+// Move moves Icarus one step in the requested direction.
+func (g *grpcServer) Move(ctx context.Context, req *mazepb.MoveRequest) (*mazepb.Reply, error) {
+	return grpcReply(g.s.move(req.GetSession(), req.GetDirection().Mazelib().String()))
+}
+
+// Done ends the session and flushes its results.
+func (g *grpcServer) Done(ctx context.Context, req *mazepb.DoneRequest) (*mazepb.Reply, error) {
+	return grpcReply(g.s.end(req.GetSession()))
+}
+
+// Play plays a single session over a stream, answering each command with a reply.
+// The session ends when the stream is closed.
+func (g *grpcServer) Play(stream mazepb.Daedalus_PlayServer) error {
+	var id string
+	defer func() {
+		if id != "" {
+			_, _ = g.s.end(id)
+		}
+	}()
+
+	for {
+		cmd, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := stream.Send(mazepb.FromReply(g.s.exec(&id, cmd.Mazelib()))); err != nil {
+			return err
+		}
+	}
+}
+
+// grpcReply converts a reply and its HTTP status code into the result of a unary RPC.
+// Error replies are turned into gRPC status errors.
+func grpcReply(r mazelib.Reply, code int) (*mazepb.Reply, error) {
+	if code == http.StatusOK {
+		return mazepb.FromReply(r), nil
+	}
+	return nil, status.Error(grpcCode(r.Code), r.Message)
+}
+
+// grpcCode returns the gRPC status code corresponding to c.
+func grpcCode(c mazelib.ErrorCode) codes.Code {
+	switch c {
+	case mazelib.CodeWall, mazelib.CodeFinished:
+		return codes.FailedPrecondition
+	case mazelib.CodeOutOfBounds:
+		return codes.OutOfRange
+	case mazelib.CodeBadDirection:
+		return codes.InvalidArgument
+	case mazelib.CodeNoSession:
+		return codes.NotFound
+	case mazelib.CodeUnauthorized:
+		return codes.Unauthenticated
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/skatsuta/labyrinth/mazepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func dialGRPC(t *testing.T, s *server) mazepb.DaedalusClient {
+	lis := bufconn.Listen(1 << 16)
+	gs := newGRPCServer(s)
+	go func() {
+		_ = gs.Serve(lis)
+	}()
+	t.Cleanup(gs.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("error dialing: %v", err)
+	}
+	t.Cleanup(func() { _ = cc.Close() })
+
+	return mazepb.NewDaedalusClient(cc)
+}
+
+func TestGRPCUnary(t *testing.T) {
+	sink := &recordSink{}
+	c := dialGRPC(t, newServer(sink))
+	ctx := context.Background()
+
+	_, err := c.Move(ctx, &mazepb.MoveRequest{Session: "nobody", Direction: mazepb.Direction_DIRECTION_UP})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("move in unknown session: got %v; want %v", err, codes.NotFound)
+	}
+
+	r, err := c.Awake(ctx, &mazepb.AwakeRequest{})
+	if err != nil || r.GetSession() == "" {
+		t.Fatalf("awake: got %v, %v", r, err)
+	}
+
+	if _, err := c.Done(ctx, &mazepb.DoneRequest{Session: r.GetSession()}); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if len(sink.results) != 1 {
+		t.Errorf("got %d results; want 1", len(sink.results))
+	}
+}
+
+func TestGRPCPlay(t *testing.T) {
+	sink := &recordSink{}
+	c := dialGRPC(t, newServer(sink))
+
+	stream, err := c.Play(context.Background())
+	if err != nil {
+		t.Fatalf("play: %v", err)
+	}
+
+	tests := []struct {
+		cmd     *mazepb.Command
+		wantErr bool
+	}{
+		{&mazepb.Command{Action: mazepb.Command_ACTION_AWAKE}, false},
+		{&mazepb.Command{Action: mazepb.Command_ACTION_UNSPECIFIED}, true},
+		{&mazepb.Command{Action: mazepb.Command_ACTION_DONE}, false},
+	}
+
+	for _, tt := range tests {
+		if err := stream.Send(tt.cmd); err != nil {
+			t.Fatalf("send %v: %v", tt.cmd, err)
+		}
+		r, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("recv reply to %v: %v", tt.cmd, err)
+		}
+		if r.GetError() != tt.wantErr {
+			t.Errorf("%v: got error %t; want %t", tt.cmd, r.GetError(), tt.wantErr)
+		}
+	}
+
+	if len(sink.results) != 1 {
+		t.Errorf("got %d results; want 1", len(sink.results))
+	}
+}
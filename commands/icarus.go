@@ -16,11 +16,13 @@
 package commands
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"time"
 
 	"github.com/skatsuta/labyrinth/log"
 	"github.com/skatsuta/labyrinth/mazelib"
@@ -40,8 +42,11 @@ var icarusCmd = &cobra.Command{
   and then can discover if his new cell has walls on each of the four sides.
 
   Icarus can connect to a Daedalus and solve many laybrinths at a time.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		RunIcarus()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		return RunIcarus(ctx)
 	},
 }
 
@@ -49,100 +54,105 @@ func init() {
 	RootCmd.AddCommand(icarusCmd)
 }
 
-// RunIcarus runs the solver as many times as the user desires.
-func RunIcarus() {
-	// Run the solver as many times as the user desires.
-	fmt.Println("Solving", viper.GetInt("times"), "times")
-	for x := 0; x < viper.GetInt("times"); x++ {
+// doneTimeout is how long Icarus waits for Daedalus to end the session.
+const doneTimeout = 5 * time.Second
 
-		solveMaze()
+// RunIcarus runs the solver against a Daedalus server as many times as the user desires,
+// or until ctx is done.
+func RunIcarus(ctx context.Context) error {
+	if err := waitForServer(ctx); err != nil {
+		return err
 	}
 
-	// Once we have solved the maze the required times, tell daedalus we are done
-	_, _ = makeRequest("http://127.0.0.1:" + viper.GetString("port") + "/done")
-}
-
-// Make a call to the laybrinth server (daedalus) that icarus is ready to wake up
-func awake() mazelib.Survey {
-	contents, err := makeRequest("http://127.0.0.1:" + viper.GetString("port") + "/awake")
+	t, err := newTransport(viper.GetString("transport"))
 	if err != nil {
-		fmt.Println(err)
+		return err
+	}
+	if c, ok := t.(io.Closer); ok {
+		defer func() {
+			_ = c.Close()
+		}()
 	}
-	r := ToReply(contents)
-	return r.Survey
-}
 
-// Move makes a call to the laybrinth server (daedalus)
-// to move Icarus a given direction
-// Will be used heavily by solveMaze
-func Move(direction string) (mazelib.Survey, error) {
-	if direction == "left" || direction == "right" || direction == "up" || direction == "down" {
+	return runIcarus(ctx, t)
+}
 
-		contents, err := makeRequest("http://127.0.0.1:" + viper.GetString("port") + "/move/" + direction)
-		if err != nil {
-			return mazelib.Survey{}, err
+// runIcarus runs the solver through t as many times as the user desires,
+// or until ctx is done.
+func runIcarus(ctx context.Context, t transport) error {
+	// Run the solver as many times as the user desires.
+	l := log.FromContext(ctx)
+	fmt.Println("Solving", viper.GetInt("times"), "times")
+	var dbg *debugger
+	if viper.GetBool("interactive") {
+		dbg = newDebugger(os.Stdin, os.Stdout)
+		fmt.Print(debuggerHelp)
+	}
+	for x := 0; x < viper.GetInt("times"); x++ {
+		if ctx.Err() != nil {
+			l.Warnf("interrupted after solving %d times\n", x)
+			break
 		}
 
-		rep := ToReply(contents)
-		if rep.Victory {
-			fmt.Println(rep.Message)
-			// os.Exit(1)
-			return rep.Survey, mazelib.ErrVictory
-		}
-		return rep.Survey, errors.New(rep.Message)
+		solveMaze(ctx, t, dbg)
 	}
 
-	return mazelib.Survey{}, errors.New("invalid direction")
+	// Once we have solved the maze the required times, tell daedalus we are done,
+	// even if we have been interrupted.
+	dctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), doneTimeout)
+	defer cancel()
+	_, err := t.Done(dctx)
+	return err
 }
 
-// utility function to wrap making requests to the daedalus server
-func makeRequest(url string) ([]byte, error) {
-	response, err := http.Get(url)
+// Make a call to the laybrinth server (daedalus) that icarus is ready to wake up
+func awake(ctx context.Context, t transport) mazelib.Reply {
+	r, err := t.Awake(ctx)
 	if err != nil {
-		return nil, err
+		log.FromContext(ctx).Errorf("%v", err)
 	}
-	defer func() {
-		_ = response.Body.Close()
-	}()
-	contents, err := ioutil.ReadAll(response.Body)
+	return r
+}
+
+// Move makes a call to the laybrinth server (daedalus) through t
+// to move Icarus a given direction
+// Will be used heavily by solveMaze
+func Move(ctx context.Context, t transport, dir mazelib.Direction) (mazelib.Survey, error) {
+	rep, err := t.Move(ctx, dir)
 	if err != nil {
-		return nil, err
+		return rep.Survey, err
 	}
-	return contents, nil
-}
 
-// ToReply handles a JSON response and unmarshalling it into a reply struct
-func ToReply(in []byte) mazelib.Reply {
-	res := &mazelib.Reply{}
-	_ = json.Unmarshal(in, &res)
-	return *res
+	if rep.Victory {
+		return rep.Survey, mazelib.ErrVictory
+	}
+	return rep.Survey, nil
 }
 
-func solveMaze() {
+// solveMaze solves a maze through t, stopping at the steps dbg breaks on if it is not nil.
+func solveMaze(ctx context.Context, t transport, dbg *debugger) {
 	var (
-		sv          mazelib.Survey
-		dir         mazelib.Direction
-		err         error
-		s           = awake()
-		stack       = newStack(record{survey: s})
-		popped      bool
-		count       int
-		interactive = viper.GetBool("interactive")
+		sv     mazelib.Survey
+		dir    mazelib.Direction
+		err    error
+		r      = awake(ctx, t)
+		stack  = newStack(record{survey: r.Survey})
+		popped bool
+		count  int
+		// pos is where Icarus is relative to the start.
+		pos mazelib.Coordinate
 	)
+	// Icarus samples directions reproducibly for the seed of the maze
+	rnd := rand.New(rand.NewSource(r.Seed))
+	ml := log.FromContext(ctx).With("session", r.Session)
 
 	for stack.size() > 0 {
-		if interactive {
-			var input string
-			fmt.Print("Press Enter to move forward...")
-			fmt.Scanln(&input)
-		}
-
 		count++
-		log.Debugf("count: %d\n", count)
+		l := ml.With("step", count, "coordinate", fmt.Sprintf("%d,%d", pos.X, pos.Y))
 
 		popped = false
 		current := stack.last()
-		log.Debugf("current: %+v\n", current)
+		l.Debugf("current: %+v\n", current)
 
 		// init
 		cand := make(map[mazelib.Direction]bool)
@@ -158,12 +168,12 @@ func solveMaze() {
 		if !current.survey.Left {
 			cand[mazelib.W] = true
 		}
-		log.Debugf("direction candidates are %v\n", cand)
+		l.Debugf("direction candidates are %v\n", cand)
 
 		// delete the directions Icarus has already moved to unless it's a dead end
 		for _, d := range current.dirs {
 			if cand[d] {
-				log.Debugf("direction %s has been already moved to. deleting...\n", d.String())
+				l.Debugf("direction %s has been already moved to. deleting...\n", d.String())
 				delete(cand, d)
 			}
 		}
@@ -171,31 +181,56 @@ func solveMaze() {
 		if len(cand) == 0 {
 			switch len(current.dirs) {
 			case 0:
-				log.Warnf("no direction to move on! giving up...\n")
+				l.Warnf("no direction to move on! giving up...\n")
 				return
 			default: // move to the oldest direction
 				cand[current.dirs[0]] = true
 			}
-
-			stack.pop()
-			log.Debugf("popping from the stack: size = %d\n", stack.size())
 			popped = true
 		}
 
 		// sampling
-		for dir = range cand {
-			sv, err = Move(dir.String())
-			break
+		choices := make([]mazelib.Direction, 0, len(cand))
+		for _, d := range []mazelib.Direction{mazelib.N, mazelib.E, mazelib.S, mazelib.W} {
+			if cand[d] {
+				choices = append(choices, d)
+			}
 		}
-		log.Debugf("next: %+v\n", sv)
+		dir = choices[rnd.Intn(len(choices))]
+
+		if dbg != nil {
+			f := frame{count: count, pos: pos, event: eventMove, survey: current.survey, choices: choices, next: dir, stack: stack.stk}
+			switch {
+			case popped:
+				f.event = eventBacktrack
+			case len(choices) > 1:
+				f.event = eventJunction
+			}
+			if !dbg.pause(f) {
+				l.Warnf("quitting the debugger! giving up...\n")
+				return
+			}
+		}
+
+		if popped {
+			stack.pop()
+			l.Debugf("popping from the stack: size = %d\n", stack.size())
+		}
+
+		sv, err = Move(ctx, t, dir)
+		l.Debugf("next: %+v\n", sv)
 		if err == mazelib.ErrVictory {
-			log.Infof("Yay! Treasure discovered!\n")
+			l.Infof("Yay! Treasure discovered in %d steps!\n", count)
+			if dbg != nil {
+				dbg.pause(frame{count: count, pos: pos.Step(dir), event: eventVictory, stack: stack.stk})
+			}
 			return
 		}
-		if err.Error() != "" {
-			log.Debugf("error: %#v\n", err)
+		if err != nil {
+			l.Debugf("error: %#v\n", err)
 			return
 		}
+		pos = pos.Step(dir)
 
 		if popped {
 			continue
@@ -210,7 +245,7 @@ func solveMaze() {
 		stack.push(next)
 	}
 
-	log.Warnf("stack is now empty... maybe something wrong?\n")
+	ml.Warnf("stack is now empty... maybe something wrong?\n")
 }
 
 // record is a record of directions Icarus moved to.
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -50,3 +51,19 @@ func TestLast(t *testing.T) {
 		}
 	}
 }
+
+func TestRunIcarusLocally(t *testing.T) {
+	sink := &recordSink{}
+	s := newServer(sink)
+
+	if err := runIcarus(context.Background(), &localTransport{s: s}); err != nil {
+		t.Fatalf("runIcarus: %v", err)
+	}
+
+	if len(sink.results) != 1 {
+		t.Fatalf("got %d results; want 1", len(sink.results))
+	}
+	if got := len(sink.results[0].Scores); got != 1 {
+		t.Errorf("got %d victories; want 1", got)
+	}
+}
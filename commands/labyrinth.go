@@ -19,10 +19,14 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"time"
 
+	"github.com/skatsuta/labyrinth/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -40,6 +44,10 @@ var CfgFile string
 var RootCmd = &cobra.Command{
 	Use:   "labyrinth",
 	Short: "a labyrinth generator and solver",
+	// errors at run time, e.g. of connections, are not usage errors,
+	// and they are printed by Execute.
+	SilenceUsage:  true,
+	SilenceErrors: true,
 	Long: `In Greek mythology, Daedalus was a skillful craftsman and
 artist. He is the father of Icarus, and the creator of the Labyrinth.
 
@@ -51,41 +59,162 @@ the darkness of the Labyrinth he can only see his immediate cell and
 if there is a wall or not to the top, right, bottom and left. He takes
 one step and then can discover if his new cell has walls on each of
 the four sides.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		go RunServer()
-
-		// give server time to start before sending a request.
-		// There's a better way to do this, but I'm lazy and this is just for fun.
-		time.Sleep(1 * time.Second)
+	// every command logs through the logger configured by the flags
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		l, err := newLogger()
+		if err != nil {
+			return err
+		}
+		cmd.SetContext(log.NewContext(cmd.Context(), l))
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		return log.FromContext(cmd.Context()).Close()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
 
-		RunIcarus()
+		switch viper.GetString("transport") {
+		case "", transportLocal:
+			return runLocal(ctx)
+		default:
+			return runRemote(ctx)
+		}
 	},
 }
 
+// runLocal runs Icarus against Daedalus in the same process
+// without any server listening on the network.
+func runLocal(ctx context.Context) error {
+	s, err := newLocalServer(log.FromContext(ctx))
+	if err != nil {
+		return err
+	}
+	err = runIcarus(ctx, &localTransport{s: s, solver: viper.GetString("solver")})
+	s.flushAll()
+	return err
+}
+
+// newLocalServer returns a server configured by the flags to run in the same process,
+// which logs to l.
+func newLocalServer(l *log.Logger) (*server, error) {
+	sink, err := resultsSink(os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	s := newServer(sink)
+	s.logger = l
+	s.seeds.base = viper.GetInt64("seed")
+	if s.renderer, err = mazeRenderer(); err != nil {
+		return nil, err
+	}
+	if s.template, err = loadMazeFile(viper.GetString("maze-file")); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// runRemote runs a Daedalus server and lets Icarus connect to it over the network.
+func runRemote(ctx context.Context) error {
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ready := make(chan struct{})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- RunServer(sctx, ready)
+	}()
+
+	// wait for the server to start before sending a request.
+	select {
+	case <-ready:
+	case err := <-errc:
+		return err
+	}
+
+	ierr := RunIcarus(ctx)
+
+	// Icarus is done, so shut down the server.
+	cancel()
+	if err := <-errc; err != nil {
+		return err
+	}
+	return ierr
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Setting flags here so they can be used by both the root behavior as well as
 	// by the indidual behaviors of icarus and daedalus
 	RootCmd.PersistentFlags().StringVar(&CfgFile, "config", "", "config file (default is $CWD/config.yaml)")
+	RootCmd.PersistentFlags().String("host", "", "Host Daedalus binds to (all interfaces if empty) and Icarus connects to (127.0.0.1 if empty)")
 	RootCmd.PersistentFlags().IntP("port", "p", 8013, "Port run on")
+	RootCmd.PersistentFlags().String("server-url", "", "URL of the Daedalus server Icarus connects to, e.g. https://maze.lan:8013")
+	RootCmd.PersistentFlags().String("tls-cert", "", "TLS certificate file Daedalus serves with")
+	RootCmd.PersistentFlags().String("tls-key", "", "TLS private key file Daedalus serves with")
+	RootCmd.PersistentFlags().String("tls-ca", "", "CA certificate file Icarus trusts (default is --tls-cert, or the system roots)")
+	RootCmd.PersistentFlags().String("token", "", "bearer token Icarus must present to Daedalus")
+	RootCmd.PersistentFlags().Duration("wait", 10*time.Second, "how long Icarus waits for Daedalus to get ready")
+	RootCmd.PersistentFlags().Duration("session-ttl", 30*time.Minute, "how long Daedalus keeps a session without any request before ending it (never if 0)")
+	RootCmd.PersistentFlags().Int("grpc-port", 0, "Port the gRPC service runs on (disabled if 0)")
 	RootCmd.PersistentFlags().IntP("width", "x", 15, "width of the laybrinth")
 	RootCmd.PersistentFlags().IntP("height", "y", 10, "height of the laybrinth") // 'h' is used for help already
 	RootCmd.PersistentFlags().IntP("times", "t", 1, "times to solve the laybrinth")
 	RootCmd.PersistentFlags().IntP("max-steps", "m", 500, "Maximum steps before giving up")
-	RootCmd.PersistentFlags().BoolP("interactive", "i", false, "runs in interactive mode")
+	RootCmd.PersistentFlags().BoolP("interactive", "i", false, "runs Icarus in a step-through debugger")
 	RootCmd.PersistentFlags().BoolP("debug", "d", false, "prints debug messages")
+	RootCmd.PersistentFlags().Int64("seed", 0, "seed of the first maze; the following mazes use the following seeds (random if 0)")
+	RootCmd.PersistentFlags().String("maze-file", "", "JSON file of the maze Daedalus serves instead of generating mazes")
 	RootCmd.PersistentFlags().Float64P("braid", "b", 1.0, "probability to rearrange an dead end to a braid")
+	RootCmd.PersistentFlags().String("solver", "icarus", "name of the solver recorded in the run history")
+	RootCmd.PersistentFlags().String("history", "", "file the run history is stored in, e.g. labyrinth.db (disabled if empty)")
+	RootCmd.PersistentFlags().String("report", reportText, "format of the results of sessions: text, json or csv")
+	RootCmd.PersistentFlags().String("gif-dir", "", "directory to save the replay of every maze in as an animated GIF (disabled if empty)")
+	RootCmd.PersistentFlags().String("trace-dir", "", "directory to save the trace of every maze in as JSON for 'labyrinth replay' (disabled if empty)")
+	RootCmd.PersistentFlags().String("maze-style", "print", "characters mazes are printed in: print, ascii or box")
+	RootCmd.PersistentFlags().Bool("color", false, "prints mazes in ANSI colors")
+	RootCmd.PersistentFlags().String("log-level", "info", "least severe level of the messages logged: debug, info, warn or error (debug with --debug)")
+	RootCmd.PersistentFlags().String("log-format", "text", "format of the log: text or json")
+	RootCmd.PersistentFlags().String("log-file", "", "file to write the log to (standard output if empty)")
+	RootCmd.PersistentFlags().Int("log-max-size", 100, "size in megabytes to rotate --log-file at (never if 0)")
+	RootCmd.PersistentFlags().Int("log-max-backups", 3, "how many rotated log files are kept")
+	RootCmd.PersistentFlags().String("transport", "", "transport Icarus uses to talk to Daedalus: local, http or websocket (default local if both run in one process, otherwise http)")
 
 	// Bind viper to these flags so viper can read flag values along with config, env, etc.
 	_ = viper.BindPFlag("width", RootCmd.PersistentFlags().Lookup("width"))
 	_ = viper.BindPFlag("height", RootCmd.PersistentFlags().Lookup("height"))
+	_ = viper.BindPFlag("host", RootCmd.PersistentFlags().Lookup("host"))
 	_ = viper.BindPFlag("port", RootCmd.PersistentFlags().Lookup("port"))
+	_ = viper.BindPFlag("server-url", RootCmd.PersistentFlags().Lookup("server-url"))
+	_ = viper.BindPFlag("tls-cert", RootCmd.PersistentFlags().Lookup("tls-cert"))
+	_ = viper.BindPFlag("tls-key", RootCmd.PersistentFlags().Lookup("tls-key"))
+	_ = viper.BindPFlag("tls-ca", RootCmd.PersistentFlags().Lookup("tls-ca"))
+	_ = viper.BindPFlag("token", RootCmd.PersistentFlags().Lookup("token"))
+	_ = viper.BindPFlag("wait", RootCmd.PersistentFlags().Lookup("wait"))
+	_ = viper.BindPFlag("session-ttl", RootCmd.PersistentFlags().Lookup("session-ttl"))
+	_ = viper.BindPFlag("grpc-port", RootCmd.PersistentFlags().Lookup("grpc-port"))
 	_ = viper.BindPFlag("times", RootCmd.PersistentFlags().Lookup("times"))
 	_ = viper.BindPFlag("max-steps", RootCmd.PersistentFlags().Lookup("max-steps"))
 	_ = viper.BindPFlag("interactive", RootCmd.PersistentFlags().Lookup("interactive"))
 	_ = viper.BindPFlag("debug", RootCmd.PersistentFlags().Lookup("debug"))
+	_ = viper.BindPFlag("seed", RootCmd.PersistentFlags().Lookup("seed"))
+	_ = viper.BindPFlag("maze-file", RootCmd.PersistentFlags().Lookup("maze-file"))
 	_ = viper.BindPFlag("braid", RootCmd.PersistentFlags().Lookup("braid"))
+	_ = viper.BindPFlag("solver", RootCmd.PersistentFlags().Lookup("solver"))
+	_ = viper.BindPFlag("history", RootCmd.PersistentFlags().Lookup("history"))
+	_ = viper.BindPFlag("report", RootCmd.PersistentFlags().Lookup("report"))
+	_ = viper.BindPFlag("gif-dir", RootCmd.PersistentFlags().Lookup("gif-dir"))
+	_ = viper.BindPFlag("trace-dir", RootCmd.PersistentFlags().Lookup("trace-dir"))
+	_ = viper.BindPFlag("maze-style", RootCmd.PersistentFlags().Lookup("maze-style"))
+	_ = viper.BindPFlag("color", RootCmd.PersistentFlags().Lookup("color"))
+	_ = viper.BindPFlag("log-level", RootCmd.PersistentFlags().Lookup("log-level"))
+	_ = viper.BindPFlag("log-format", RootCmd.PersistentFlags().Lookup("log-format"))
+	_ = viper.BindPFlag("log-file", RootCmd.PersistentFlags().Lookup("log-file"))
+	_ = viper.BindPFlag("log-max-size", RootCmd.PersistentFlags().Lookup("log-max-size"))
+	_ = viper.BindPFlag("log-max-backups", RootCmd.PersistentFlags().Lookup("log-max-backups"))
+	_ = viper.BindPFlag("transport", RootCmd.PersistentFlags().Lookup("transport"))
 }
 
 // Read in config file and ENV variables if set.
@@ -106,6 +235,37 @@ func initConfig() {
 	}
 }
 
+// newLogger returns the logger configured by the flags: it writes the messages
+// of --log-level and above in --log-format to --log-file, or to standard output.
+func newLogger() (*log.Logger, error) {
+	level, err := log.ParseLevel(viper.GetString("log-level"))
+	if err != nil {
+		return nil, err
+	}
+	if viper.GetBool("debug") {
+		level = log.LevelDebug
+	}
+
+	opts := log.Options{Level: level}
+	switch format := viper.GetString("log-format"); format {
+	case "text":
+	case "json":
+		opts.JSON = true
+	default:
+		return nil, fmt.Errorf("unknown log format %q; want text or json", format)
+	}
+
+	var out io.Writer = os.Stdout
+	if path := viper.GetString("log-file"); path != "" {
+		f, err := log.OpenRotatingFile(path, int64(viper.GetInt("log-max-size"))<<20, viper.GetInt("log-max-backups"))
+		if err != nil {
+			return nil, err
+		}
+		out, opts.Time = f, true
+	}
+	return log.New(out, opts), nil
+}
+
 //Execute adds all child commands to the root command Labyrinth and sets flags appropriately.
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
@@ -0,0 +1,105 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/skatsuta/labyrinth/history"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Defining the leaderboard command.
+// This will be called as 'laybrinth leaderboard'
+var leaderboardCmd = &cobra.Command{
+	Use:   "leaderboard",
+	Short: "Show the rankings of solvers by class of mazes",
+	Long: `Leaderboard ranks the solvers in the run history by class of mazes,
+i.e. the generation algorithm and the dimensions. Solvers are ranked by
+the mean steps of their victories; those without any victory come last.
+
+The run history is read from the file given by --history.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := viper.GetString("history")
+		if path == "" {
+			return errors.New("no history file is given by --history")
+		}
+
+		class, _ := cmd.Flags().GetString("class")
+		solver, _ := cmd.Flags().GetString("only-solver")
+		return showLeaderboard(os.Stdout, path, class, solver)
+	},
+}
+
+func init() {
+	leaderboardCmd.Flags().String("class", "", "show only the class of mazes, e.g. \"recursive-backtracker 15x10\"")
+	leaderboardCmd.Flags().String("only-solver", "", "show only the solver")
+	RootCmd.AddCommand(leaderboardCmd)
+}
+
+// showLeaderboard prints the leaderboard of the run history stored at path to w.
+func showLeaderboard(w io.Writer, path, class, solver string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	st, err := history.Open(path)
+	if err != nil {
+		return err
+	}
+	runs, err := st.Runs()
+	if cerr := st.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return printLeaderboard(w, history.Rank(runs), class, solver)
+}
+
+// printLeaderboard prints rankings to w as a table, numbering solvers within each class.
+// Rankings not matching class or solver are omitted unless they are empty.
+func printLeaderboard(w io.Writer, rankings []history.Ranking, class, solver string) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CLASS\tRANK\tSOLVER\tRUNS\tWIN%\tMEAN STEPS\tBEST\tMEAN TIME")
+
+	var prev string
+	var n int
+	for _, rk := range rankings {
+		if rk.Class != prev {
+			prev, n = rk.Class, 0
+		}
+		n++
+		if (class != "" && rk.Class != class) || (solver != "" && rk.Solver != solver) {
+			continue
+		}
+
+		mean, best := "-", "-"
+		if rk.Victories > 0 {
+			mean = fmt.Sprintf("%.1f", rk.MeanSteps)
+			best = fmt.Sprint(rk.BestSteps)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%.0f\t%s\t%s\t%s\n",
+			rk.Class, n, rk.Solver, rk.Runs, 100*rk.WinRate(), mean, best, rk.MeanDuration.Round(time.Millisecond))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skatsuta/labyrinth/history"
+)
+
+func TestShowLeaderboard(t *testing.T) {
+	const big, small = "recursive-backtracker 15x10", "recursive-backtracker 5x5"
+	run := func(solver string, w, h, steps int, outcome history.Outcome) history.Run {
+		return history.Run{Solver: solver, Width: w, Height: h, Algorithm: algorithmBacktracker, Steps: steps, Outcome: outcome}
+	}
+
+	path := filepath.Join(t.TempDir(), "history.db")
+	st, err := history.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.Add(
+		run("minos", 15, 10, 10, history.Victory),
+		run("icarus", 15, 10, 8, history.Victory),
+		run("theseus", 15, 10, 30, history.Abandoned),
+		run("minos", 15, 10, 12, history.Victory),
+		run("icarus", 5, 5, 4, history.Victory),
+		run("icarus", 15, 10, 50, history.Abandoned),
+	)
+	if cerr := st.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		class, solver string
+		// want holds the class, rank and solver of each row
+		want []string
+	}{
+		{"", "", []string{big + " 1 icarus", big + " 2 minos", big + " 3 theseus", small + " 1 icarus"}},
+		{small, "", []string{small + " 1 icarus"}},
+		{"", "minos", []string{big + " 2 minos"}},
+		{"", "icarus", []string{big + " 1 icarus", small + " 1 icarus"}},
+		{big, "nobody", nil},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := showLeaderboard(&buf, path, tt.class, tt.solver); err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		var got []string
+		for _, l := range lines[1:] {
+			f := strings.Fields(l)
+			got = append(got, strings.Join(f[:4], " "))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("class %q, solver %q: got rows %q; want %q", tt.class, tt.solver, got, tt.want)
+		}
+	}
+}
+
+func TestShowLeaderboardNoHistory(t *testing.T) {
+	if err := showLeaderboard(&bytes.Buffer{}, filepath.Join(t.TempDir(), "none.db"), "", ""); err == nil {
+		t.Error("a missing history file should be an error")
+	}
+}
@@ -0,0 +1,390 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+
+	"github.com/skatsuta/labyrinth/log"
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// humanSolver is the solver recorded for the games played by people
+// unless --solver is given.
+const humanSolver = "human"
+
+// ANSI escape sequences hiding and showing the cursor.
+const (
+	hideCursor = "\x1b[?25l"
+	showCursor = "\x1b[?25h"
+)
+
+// Defining the play command.
+// This will be called as 'laybrinth play'
+var playCmd = &cobra.Command{
+	Use:   "play",
+	Short: "Steer Icarus through laybrinths yourself",
+	Long: `Play lets you steer Icarus with the arrow keys, WASD or hjkl.
+Like Icarus, you see only the rooms you have been to, and the map of
+them is drawn as you explore. Press n for a new laybrinth and q to quit.
+
+The game talks to Daedalus in the same way as Icarus does: in the same
+process by default, or to the server at --server-url with --transport.
+Your runs are recorded as the solver "human" unless --solver is given,
+so they land on the same leaderboard.
+
+Play sets up the terminal with stty, so it runs only on Unix-like systems
+such as Linux and macOS, and not in the Windows console.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		name, _ := cmd.Flags().GetString("style")
+		style, ok := mazelib.Styles[name]
+		if !ok {
+			return fmt.Errorf("unknown maze style %q", name)
+		}
+		r := mazelib.Renderer{Style: style, Color: viper.GetBool("color")}
+		if !cmd.Flag("solver").Changed {
+			viper.Set("solver", humanSolver)
+		}
+
+		var (
+			t transport
+			s *server
+		)
+		switch viper.GetString("transport") {
+		case "", transportLocal:
+			var err error
+			if s, err = newLocalServer(log.FromContext(ctx)); err != nil {
+				return err
+			}
+			// the maze must not be printed under the game
+			s.out = io.Discard
+			t = &localTransport{s: s, solver: viper.GetString("solver")}
+		default:
+			if err := waitForServer(ctx); err != nil {
+				return err
+			}
+			var err error
+			if t, err = newTransport(viper.GetString("transport")); err != nil {
+				return err
+			}
+			if c, ok := t.(io.Closer); ok {
+				defer func() {
+					_ = c.Close()
+				}()
+			}
+		}
+
+		restore, err := rawTerminal(os.Stdin)
+		if err != nil {
+			return err
+		}
+		fmt.Print(hideCursor)
+		err = playGame(ctx, t, readKeys(os.Stdin), os.Stdout, r)
+		fmt.Print(showCursor)
+		restore()
+
+		dctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), doneTimeout)
+		defer cancel()
+		if _, derr := t.Done(dctx); err == nil {
+			err = derr
+		}
+		if s != nil {
+			s.flushAll()
+		}
+		return err
+	},
+}
+
+func init() {
+	playCmd.Flags().String("style", "box", "characters the laybrinth is drawn in: ascii or box")
+	RootCmd.AddCommand(playCmd)
+}
+
+// rawTerminal sets the terminal on f to pass keys as soon as they are
+// pressed without echoing them, and returns a function restoring it.
+// It needs stty, which Windows does not have.
+func rawTerminal(f *os.File) (func(), error) {
+	if runtime.GOOS == "windows" {
+		return nil, errors.New("play is not supported on Windows, which has no stty to set up the terminal")
+	}
+
+	stty := func(args ...string) (string, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = f
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("play needs a terminal: %w", err)
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, fmt.Errorf("play needs a terminal: %w", err)
+	}
+	return func() {
+		_, _ = stty(saved)
+	}, nil
+}
+
+// key is a key a player presses.
+type key int
+
+// Keys of the game.
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyNew
+	keyQuit
+)
+
+// keyDirections maps the keys moving Icarus to the directions.
+var keyDirections = map[key]mazelib.Direction{
+	keyUp:    mazelib.N,
+	keyDown:  mazelib.S,
+	keyLeft:  mazelib.W,
+	keyRight: mazelib.E,
+}
+
+// readKeys reads the keys pressed from r and sends them to the returned channel,
+// which is closed when r ends.
+func readKeys(r io.Reader) <-chan key {
+	keys := make(chan key)
+	go func() {
+		defer close(keys)
+		br := bufio.NewReader(r)
+		for {
+			b, err := br.ReadByte()
+			if err != nil {
+				return
+			}
+
+			k := keyNone
+			switch b {
+			case '\x1b':
+				// the arrow keys send ESC [ A to D, or ESC O A to D
+				if next, err := br.ReadByte(); err != nil || (next != '[' && next != 'O') {
+					continue
+				}
+				b, err = br.ReadByte()
+				if err != nil {
+					return
+				}
+				k = map[byte]key{'A': keyUp, 'B': keyDown, 'C': keyRight, 'D': keyLeft}[b]
+			case 'w', 'k':
+				k = keyUp
+			case 's', 'j':
+				k = keyDown
+			case 'a', 'h':
+				k = keyLeft
+			case 'd', 'l':
+				k = keyRight
+			case 'n':
+				k = keyNew
+			case 'q', '\x03', '\x04': // ctrl+c and ctrl+d as well
+				k = keyQuit
+			}
+			if k != keyNone {
+				keys <- k
+			}
+		}
+	}()
+	return keys
+}
+
+// chart is the map of a maze a player draws while exploring it,
+// in coordinates relative to the start.
+type chart struct {
+	rooms   map[mazelib.Coordinate]*mazelib.Sight
+	icarus  mazelib.Coordinate
+	steps   int
+	victory bool
+}
+
+// newChart returns a chart of a maze whose start has been surveyed as s.
+func newChart(s mazelib.Survey) *chart {
+	return &chart{
+		rooms: map[mazelib.Coordinate]*mazelib.Sight{
+			{}: {Walls: s, Visits: 1, Start: true},
+		},
+	}
+}
+
+// move records that Icarus has moved in dir and Daedalus has replied r.
+func (c *chart) move(dir mazelib.Direction, r mazelib.Reply) {
+	c.icarus = c.icarus.Step(dir)
+	c.steps++
+	room, ok := c.rooms[c.icarus]
+	if !ok {
+		room = &mazelib.Sight{Walls: r.Survey}
+		c.rooms[c.icarus] = room
+	}
+	room.Visits++
+	if r.Victory {
+		room.Treasure = true
+		c.victory = true
+	}
+}
+
+// sighting returns the rooms on c within the smallest rectangle containing them.
+func (c *chart) sighting() mazelib.Sighting {
+	lo, hi := c.icarus, c.icarus
+	for p := range c.rooms {
+		lo.X, lo.Y = min(lo.X, p.X), min(lo.Y, p.Y)
+		hi.X, hi.Y = max(hi.X, p.X), max(hi.Y, p.Y)
+	}
+
+	sg := mazelib.Sighting{
+		Width:  hi.X - lo.X + 1,
+		Height: hi.Y - lo.Y + 1,
+		Icarus: mazelib.Coordinate{X: c.icarus.X - lo.X, Y: c.icarus.Y - lo.Y},
+		Steps:  c.steps,
+	}
+	sg.Victory = c.victory
+	sg.Rooms = make([][]*mazelib.Sight, sg.Height)
+	for y := range sg.Rooms {
+		sg.Rooms[y] = make([]*mazelib.Sight, sg.Width)
+		for x := range sg.Rooms[y] {
+			sg.Rooms[y][x] = c.rooms[mazelib.Coordinate{X: x + lo.X, Y: y + lo.Y}]
+		}
+	}
+	return sg
+}
+
+// game is a game a player plays through a transport.
+type game struct {
+	t        transport
+	out      io.Writer
+	renderer mazelib.Renderer
+	chart    *chart
+	solved   int
+	// message is shown under the map until the next key.
+	message string
+}
+
+// playGame lets a player steer Icarus through t with keys, drawing the game on out,
+// until the player quits, keys are closed, or ctx is done.
+func playGame(ctx context.Context, t transport, keys <-chan key, out io.Writer, r mazelib.Renderer) error {
+	g := &game{t: t, out: out, renderer: r}
+	if err := g.awake(ctx); err != nil {
+		return err
+	}
+
+	for {
+		if err := g.draw(); err != nil {
+			return err
+		}
+
+		var k key
+		select {
+		case <-ctx.Done():
+			return nil
+		case next, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			k = next
+		}
+
+		g.message = ""
+		switch k {
+		case keyQuit:
+			return nil
+		case keyNew:
+			if err := g.awake(ctx); err != nil {
+				return err
+			}
+		default:
+			if err := g.move(ctx, keyDirections[k]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// awake starts a new maze.
+func (g *game) awake(ctx context.Context) error {
+	r, err := g.t.Awake(ctx)
+	if err != nil {
+		return err
+	}
+	g.chart = newChart(r.Survey)
+	return nil
+}
+
+// move moves Icarus in dir unless he has found the treasure.
+func (g *game) move(ctx context.Context, dir mazelib.Direction) error {
+	if g.chart.victory {
+		return nil
+	}
+
+	r, err := g.t.Move(ctx, dir)
+	switch {
+	case errors.Is(err, mazelib.ErrWall):
+		g.message = "Ouch! There is a wall."
+		return nil
+	case err != nil:
+		return err
+	}
+
+	g.chart.move(dir, r)
+	if r.Victory {
+		g.solved++
+	}
+	return nil
+}
+
+// draw draws the game on g.out.
+func (g *game) draw() error {
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	fmt.Fprintf(&b, "Labyrinth   steps: %d   solved: %d\n\n", g.chart.steps, g.solved)
+	if err := g.renderer.RenderSighting(&b, g.chart.sighting()); err != nil {
+		return err
+	}
+	b.WriteString("\n")
+
+	switch {
+	case g.chart.victory:
+		fmt.Fprintf(&b, "*** You found the treasure in %d steps! ***\n", g.chart.steps)
+		b.WriteString("Press n for another laybrinth or q to quit.\n")
+	default:
+		if g.message != "" {
+			b.WriteString(g.message + "\n")
+		}
+		b.WriteString("arrows/WASD/hjkl: move   n: new laybrinth   q: quit\n")
+	}
+
+	_, err := io.WriteString(g.out, b.String())
+	return err
+}
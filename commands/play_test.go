@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+func TestReadKeys(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []key
+	}{
+		{"\x1b[A\x1b[B\x1b[C\x1b[D", []key{keyUp, keyDown, keyRight, keyLeft}},
+		{"\x1bOA\x1bOD", []key{keyUp, keyLeft}},
+		{"wasdkjhl", []key{keyUp, keyLeft, keyDown, keyRight, keyUp, keyDown, keyLeft, keyRight}},
+		{"nq\x03\x04", []key{keyNew, keyQuit, keyQuit, keyQuit}},
+		{"x \x1bxw", []key{keyUp}},
+	}
+
+	for _, tt := range tests {
+		var got []key
+		for k := range readKeys(strings.NewReader(tt.in)) {
+			got = append(got, k)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v; want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPlayGame(t *testing.T) {
+	m := createUshapedMaze()
+	_ = m.SetStartPoint(0, 0)
+	_ = m.SetTreasure(0, 1)
+	d := m.data()
+
+	sink := &recordSink{}
+	s := newServer(sink)
+	s.template, s.out = &d, &bytes.Buffer{}
+	tr := &localTransport{s: s, solver: humanSolver}
+
+	// bump into the wall below, then go round to the treasure,
+	// and try to move on after the victory
+	keys := readKeys(strings.NewReader("\x1b[B\x1b[C\x1b[B\x1b[Dwq"))
+	var out bytes.Buffer
+	if err := playGame(context.Background(), tr, keys, &out, mazelib.Renderer{Style: mazelib.StyleASCII}); err != nil {
+		t.Fatal(err)
+	}
+
+	frames := strings.Split(out.String(), clearScreen)[1:]
+	if len(frames) != 6 {
+		t.Fatalf("got %d frames; want 6:\n%s", len(frames), out.String())
+	}
+	if !strings.Contains(frames[1], "wall") {
+		t.Errorf("bumping into a wall should be told:\n%s", frames[1])
+	}
+	last := frames[len(frames)-1]
+	for _, want := range []string{"steps: 3", "solved: 1", "You found the treasure in 3 steps!"} {
+		if !strings.Contains(last, want) {
+			t.Errorf("the victory screen should contain %q:\n%s", want, last)
+		}
+	}
+
+	if _, err := tr.Done(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.results) != 1 || !reflect.DeepEqual(sink.results[0].Scores, []int{3}) {
+		t.Fatalf("got results %+v; want a score of 3", sink.results)
+	}
+	if got := sink.results[0].Runs[0].Solver; got != humanSolver {
+		t.Errorf("got solver %q; want %q", got, humanSolver)
+	}
+}
+
+func TestChartSighting(t *testing.T) {
+	c := newChart(mazelib.Survey{Top: true, Bottom: true})
+	c.move(mazelib.W, mazelib.Reply{Survey: mazelib.Survey{Top: true, Left: true}})
+	c.move(mazelib.S, mazelib.Reply{Survey: mazelib.Survey{Bottom: true, Left: true, Right: true}})
+
+	sg := c.sighting()
+	if sg.Width != 2 || sg.Height != 2 {
+		t.Fatalf("got %dx%d; want 2x2", sg.Width, sg.Height)
+	}
+	if want := (mazelib.Coordinate{X: 0, Y: 1}); sg.Icarus != want {
+		t.Errorf("got Icarus at %v; want %v", sg.Icarus, want)
+	}
+	if room := sg.Rooms[0][1]; room == nil || !room.Start || room.Visits != 1 {
+		t.Errorf("got start %+v", room)
+	}
+	if sg.Rooms[1][1] != nil {
+		t.Errorf("an unexplored room should be unknown: got %+v", sg.Rooms[1][1])
+	}
+	if sg.Steps != 2 {
+		t.Errorf("got %d steps; want 2", sg.Steps)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skatsuta/labyrinth/log"
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Defining the render command.
+// This will be called as 'laybrinth render'
+var renderCmd = &cobra.Command{
+	Use:   "render [file]",
+	Short: "Render a laybrinth as an SVG or PNG image",
+	Long: `Render draws the laybrinth in file, in any format convert reads,
+as an SVG or PNG image. If file is omitted, a new laybrinth is generated
+from --seed, --width and --height. The image is a PNG if --format is png
+or the output file ends in .png.
+
+With --solve, Icarus solves the laybrinth in the same process and
+his path is drawn in blue. With --optimal, a shortest path from the
+start to the treasure is drawn in dashed red.
+
+PNG images can be themed with --theme (light, dark or print), and
+--heatmap colors the rooms by their distances from the start.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			d   mazelib.MazeData
+			err error
+		)
+		if len(args) > 0 {
+			d, err = readMaze(args[0])
+		} else {
+			d, err = generateMaze()
+		}
+		if err != nil {
+			return err
+		}
+
+		out, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+		if format == "" {
+			format = imageSVG
+			if strings.EqualFold(filepath.Ext(out), ".png") {
+				format = imagePNG
+			}
+		}
+
+		cell, _ := cmd.Flags().GetInt("cell")
+		optimal, _ := cmd.Flags().GetBool("optimal")
+		var path []mazelib.Coordinate
+		if solve, _ := cmd.Flags().GetBool("solve"); solve {
+			if path, err = solvePath(cmd.Context(), d); err != nil {
+				return err
+			}
+		}
+
+		var render func(w io.Writer) error
+		switch format {
+		case imageSVG:
+			opts := mazelib.SVGOptions{CellSize: cell, Path: path, Optimal: optimal}
+			render = func(w io.Writer) error { return mazelib.RenderSVG(w, d, opts) }
+		case imagePNG:
+			name, _ := cmd.Flags().GetString("theme")
+			theme, ok := mazelib.Themes[name]
+			if !ok {
+				return fmt.Errorf("unknown theme %q", name)
+			}
+			opts := mazelib.PNGOptions{CellSize: cell, Theme: theme, Path: path, Optimal: optimal}
+			opts.WallThickness, _ = cmd.Flags().GetInt("wall")
+			opts.Heatmap, _ = cmd.Flags().GetBool("heatmap")
+			render = func(w io.Writer) error { return mazelib.RenderPNG(w, d, opts) }
+		default:
+			return fmt.Errorf("unknown image format %q", format)
+		}
+
+		if out == "-" {
+			return render(os.Stdout)
+		}
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		if err := render(f); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	},
+}
+
+func init() {
+	renderCmd.Flags().StringP("output", "o", "maze.svg", `file to write the image to ("-" for standard output)`)
+	renderCmd.Flags().Int("cell", mazelib.DefaultCellSize, "size of a room in pixels")
+	renderCmd.Flags().Bool("solve", false, "draw the path of Icarus solving the laybrinth")
+	renderCmd.Flags().Bool("optimal", false, "draw a shortest path from the start to the treasure")
+	renderCmd.Flags().String("format", "", "image format, svg or png (default is from the output file name)")
+	renderCmd.Flags().Int("wall", 0, "thickness of walls in pixels of a PNG image (default is an eighth of --cell)")
+	renderCmd.Flags().String("theme", "light", "colors of a PNG image: light, dark or print")
+	renderCmd.Flags().Bool("heatmap", false, "color the rooms of a PNG image by their distances from the start")
+	RootCmd.AddCommand(renderCmd)
+}
+
+// Image formats of the render command.
+const (
+	imageSVG = "svg"
+	imagePNG = "png"
+)
+
+// readMaze reads a maze from the file at path in any of mazeFormats.
+func readMaze(path string) (mazelib.MazeData, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return mazelib.MazeData{}, err
+	}
+	name, err := detectMazeFormat(b)
+	if err != nil {
+		return mazelib.MazeData{}, fmt.Errorf("%s: %w", path, err)
+	}
+	d, err := mazeFormats[name].unmarshal(b)
+	if err != nil {
+		return mazelib.MazeData{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// generateMaze generates a maze from --seed, --width and --height
+// as Daedalus does. A random seed is used if --seed is 0.
+func generateMaze() (mazelib.MazeData, error) {
+	seed := viper.GetInt64("seed")
+	if seed == 0 {
+		seed = randomSeed()
+	}
+	return createMaze(viper.GetInt("width"), viper.GetInt("height"), seed).data(), nil
+}
+
+// solvePath lets Icarus solve d in the same process and returns the rooms he went through.
+// Nothing is printed or logged, since the image may be written to standard output.
+func solvePath(ctx context.Context, d mazelib.MazeData) ([]mazelib.Coordinate, error) {
+	quiet := log.New(io.Discard, log.Options{})
+	ctx = log.NewContext(ctx, quiet)
+	s := newServer(multiSink{})
+	s.template, s.out, s.logger = &d, io.Discard, quiet
+	t := &pathTransport{
+		transport: &localTransport{s: s, solver: viper.GetString("solver")},
+		path:      []mazelib.Coordinate{d.Start},
+	}
+
+	solveMaze(ctx, t, nil)
+	if _, err := t.Done(ctx); err != nil {
+		return nil, err
+	}
+	return t.path, nil
+}
+
+// pathTransport is a transport which records the rooms Icarus goes through
+// as he moves from the start of a single maze.
+type pathTransport struct {
+	transport
+	path []mazelib.Coordinate
+}
+
+func (t *pathTransport) Move(ctx context.Context, dir mazelib.Direction) (mazelib.Reply, error) {
+	r, err := t.transport.Move(ctx, dir)
+	if err != nil {
+		return r, err
+	}
+
+	t.path = append(t.path, t.path[len(t.path)-1].Step(dir))
+	return r, nil
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+func TestSolvePath(t *testing.T) {
+	d := createMaze(8, 6, 3).data()
+
+	path, err := solvePath(context.Background(), d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0] != d.Start || path[len(path)-1] != d.Treasure {
+		t.Fatalf("path should go from %v to %v: got %v", d.Start, d.Treasure, path)
+	}
+	for i := 1; i < len(path); i++ {
+		dx, dy := path[i].X-path[i-1].X, path[i].Y-path[i-1].Y
+		if dx*dx+dy*dy != 1 {
+			t.Errorf("step %d from %v to %v is not to an adjacent room", i, path[i-1], path[i])
+		}
+	}
+}
+
+func TestRenderSolveToStdout(t *testing.T) {
+	b, err := mazelib.MarshalMaze(createMaze(8, 6, 3).data())
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := filepath.Join(t.TempDir(), "maze.json")
+	if err := os.WriteFile(in, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// capture everything written to standard output, not only the image
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	stdout := os.Stdout
+	os.Stdout = out
+	RootCmd.SetArgs([]string{"render", in, "--solve", "-o", "-"})
+	err = RootCmd.Execute()
+	os.Stdout = stdout
+	RootCmd.SetArgs(nil)
+	_ = renderCmd.Flags().Set("solve", "false")
+	_ = renderCmd.Flags().Set("output", "maze.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(got, []byte("<svg")) || !bytes.Contains(got, []byte("<polyline")) {
+		t.Errorf("standard output should be the SVG with the path only:\n%.300s", got)
+	}
+}
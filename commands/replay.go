@@ -0,0 +1,90 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/skatsuta/labyrinth/log"
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/spf13/cobra"
+)
+
+// Defining the replay command.
+// This will be called as 'laybrinth replay'
+var replayCmd = &cobra.Command{
+	Use:   "replay <trace>",
+	Short: "Replay a trace and check Daedalus replies the same",
+	Long: `Replay re-executes the moves recorded in a trace, saved by --trace-dir,
+against the laybrinth of the trace rebuilt in the same process, and checks
+that every reply of Daedalus matches the recorded one but the session ID.
+
+It fails at the first reply which differs, so traces attached to bug reports
+can be kept as regression fixtures.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		tr, err := mazelib.UnmarshalTrace(b)
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+
+		if err := replayTrace(tr, log.FromContext(cmd.Context())); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		fmt.Printf("%s: %d moves replayed, every reply matches\n", args[0], len(tr.Moves))
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(replayCmd)
+}
+
+// replayTrace re-executes the moves of tr on its maze served by a new server logging to l,
+// and returns an error describing the first reply which does not match.
+func replayTrace(tr mazelib.Trace, l *log.Logger) error {
+	s := newServer(multiSink{})
+	s.template, s.out, s.logger = &tr.Maze, io.Discard, l
+
+	r, _ := s.awake("", tr.Solver)
+	if err := matchReply(r, tr.Awake); err != nil {
+		return fmt.Errorf("awake: %w", err)
+	}
+	defer s.end(r.Session)
+
+	for i, m := range tr.Moves {
+		got, _ := s.move(r.Session, m.Direction)
+		if err := matchReply(got, m.Reply); err != nil {
+			return fmt.Errorf("move %d %q: %w", i+1, m.Direction, err)
+		}
+	}
+	return nil
+}
+
+// matchReply returns an error if got differs from want but the session ID.
+func matchReply(got, want mazelib.Reply) error {
+	got.Session, want.Session = "", ""
+	if got != want {
+		return fmt.Errorf("got reply %+v; want %+v", got, want)
+	}
+	return nil
+}
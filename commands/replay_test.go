@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skatsuta/labyrinth/log"
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+// tracedRun plays the U-shaped maze with a bump into a wall and an invalid direction,
+// and returns the trace of the run.
+func tracedRun(t *testing.T) mazelib.Trace {
+	m := createUshapedMaze()
+	_ = m.SetStartPoint(0, 0)
+	_ = m.SetTreasure(0, 1)
+	d := m.data()
+
+	sink := &recordSink{}
+	s := newServer(sink)
+	s.template, s.out = &d, &bytes.Buffer{}
+
+	r, _ := s.awake("", "tracer")
+	for _, dir := range []string{"down", "north", "right", "down", "left"} {
+		s.move(r.Session, dir)
+	}
+	// the trace is passed as soon as the maze is solved
+	if len(sink.traces) != 1 {
+		t.Fatalf("got %d traces after the victory; want 1", len(sink.traces))
+	}
+	s.end(r.Session)
+
+	if len(sink.traces) != 1 {
+		t.Fatalf("got %d traces after the end; want 1", len(sink.traces))
+	}
+	return sink.traces[0]
+}
+
+func TestTraceSink(t *testing.T) {
+	tr := tracedRun(t)
+	if tr.Solver != "tracer" || len(tr.Moves) != 5 || !tr.Moves[4].Reply.Victory {
+		t.Fatalf("got trace %+v; want 5 moves of tracer up to the victory", tr)
+	}
+	if !errors.Is(tr.Moves[0].Reply.Err(), mazelib.ErrWall) {
+		t.Errorf("got reply %+v to the first move; want a wall", tr.Moves[0].Reply)
+	}
+
+	dir := t.TempDir()
+	if err := (traceSink{dir: dir}).Trace("s", 1, tr); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "s-1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := mazelib.UnmarshalTrace(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := replayTrace(got, log.New(&buf, log.Options{})); err != nil {
+		t.Errorf("the saved trace should replay: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Victory achieved in 3 steps") {
+		t.Errorf("the victory should be logged to the given logger: got %q", buf.String())
+	}
+}
+
+func TestReplayTraceMismatch(t *testing.T) {
+	tr := tracedRun(t)
+	// pretend Daedalus had let Icarus through the wall
+	tr.Moves[0].Reply = mazelib.Reply{Survey: mazelib.Survey{Top: true, Bottom: true, Left: true}}
+
+	err := replayTrace(tr, log.New(io.Discard, log.Options{}))
+	if err == nil || !strings.HasPrefix(err.Error(), `move 1 "down": got reply`) {
+		t.Errorf("got error %v; want a mismatch at move 1", err)
+	}
+}
+
+func TestRunIcarusTraced(t *testing.T) {
+	sink := &recordSink{}
+	s := newServer(sink)
+	if err := runIcarus(context.Background(), &localTransport{s: s}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink.traces) != 1 {
+		t.Fatalf("got %d traces; want 1", len(sink.traces))
+	}
+	for _, tr := range sink.traces {
+		if err := replayTrace(tr, log.New(io.Discard, log.Options{})); err != nil {
+			t.Errorf("seed %d: %v", tr.Seed, err)
+		}
+	}
+}
+
+func TestTraceFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := newServer(multiSink{&recordSink{}, traceSink{dir: dir}})
+	s.out = &bytes.Buffer{}
+
+	r, _ := s.awake("", "tracer")
+	if _, err := os.Stat(filepath.Join(dir, r.Session+"-1.json")); !os.IsNotExist(err) {
+		t.Errorf("the trace of a maze in progress should not be saved: %v", err)
+	}
+	s.awake(r.Session, "")
+	if _, err := os.Stat(filepath.Join(dir, r.Session+"-1.json")); err != nil {
+		t.Errorf("the trace of an abandoned maze should be saved at once: %v", err)
+	}
+	s.end(r.Session)
+	if _, err := os.Stat(filepath.Join(dir, r.Session+"-2.json")); err != nil {
+		t.Errorf("the trace of the last maze should be saved at the end: %v", err)
+	}
+}
+
+func TestUntraced(t *testing.T) {
+	s := newServer(multiSink{})
+	s.out = &bytes.Buffer{}
+
+	r, _ := s.awake("", "")
+	s.move(r.Session, "up")
+	sess, _ := s.sessions.get(r.Session)
+	if sess.trace.Maze.Width != 0 || len(sess.trace.Moves) != 0 {
+		t.Errorf("got trace %+v; want nothing recorded without a trace sink", sess.trace)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+// Formats of the report of results.
+const (
+	reportText = "text"
+	reportJSON = "json"
+	reportCSV  = "csv"
+)
+
+// csvHeader is the header row of a report in CSV.
+var csvHeader = []string{
+	"session", "count", "mean", "min", "max", "median", "p90", "p99",
+	"stddev", "ci95_low", "ci95_high", "ratio",
+}
+
+// reportSink is a ResultsSink which reports the statistics of the results to w.
+// Reports in JSON are written one object per line,
+// and reports in CSV share a single header row.
+type reportSink struct {
+	mu          sync.Mutex
+	w           io.Writer
+	format      string
+	wroteHeader bool
+}
+
+// newReportSink returns a reportSink which writes to w in format.
+func newReportSink(w io.Writer, format string) (*reportSink, error) {
+	switch format {
+	case reportText, reportJSON, reportCSV:
+		return &reportSink{w: w, format: format}, nil
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// Flush reports the statistics of r.
+func (p *reportSink) Flush(r Results) error {
+	st := mazelib.NewStats(r.Scores, r.Shortest)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch p.format {
+	case reportJSON:
+		return json.NewEncoder(p.w).Encode(struct {
+			Session string `json:"session"`
+			mazelib.Stats
+		}{r.Session, st})
+	case reportCSV:
+		cw := csv.NewWriter(p.w)
+		if !p.wroteHeader {
+			_ = cw.Write(csvHeader)
+			p.wroteHeader = true
+		}
+		f := func(x float64) string { return strconv.FormatFloat(x, 'f', 2, 64) }
+		_ = cw.Write([]string{
+			r.Session, strconv.Itoa(st.Count), f(st.Mean), strconv.Itoa(st.Min), strconv.Itoa(st.Max),
+			f(st.Median), f(st.P90), f(st.P99), f(st.StdDev), f(st.CILow), f(st.CIHigh), f(st.Ratio),
+		})
+		cw.Flush()
+		return cw.Error()
+	default:
+		return writeTextReport(p.w, st)
+	}
+}
+
+// writeTextReport writes st to w in a human-readable form.
+func writeTextReport(w io.Writer, st mazelib.Stats) error {
+	if _, err := fmt.Fprintf(w, "Labyrinth solved %d times with an avg of %.1f steps\n", st.Count, st.Mean); err != nil {
+		return err
+	}
+	if st.Count == 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(w, "  min %d, median %.1f, p90 %.1f, p99 %.1f, max %d\n"+
+		"  stddev %.1f, 95%% CI of mean [%.1f, %.1f]\n",
+		st.Min, st.Median, st.P90, st.P99, st.Max, st.StdDev, st.CILow, st.CIHigh)
+	if err != nil || st.Ratio == 0 {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "  %.2f times the shortest path on average\n", st.Ratio)
+	return err
+}
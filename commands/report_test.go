@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReportSink(t *testing.T) {
+	results := []Results{
+		{Session: "a", Scores: []int{3, 5}, Shortest: []int{3, 5}},
+		{Session: "b"},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{reportCSV, "session,count,mean,min,max,median,p90,p99,stddev,ci95_low,ci95_high,ratio\n" +
+			"a,2,4.00,3,5,4.00,4.80,4.98,1.41,-8.71,16.71,1.00\n" +
+			"b,0,0.00,0,0,0.00,0.00,0.00,0.00,0.00,0.00,0.00\n"},
+		{reportJSON, `{"session":"a","count":2,"mean":4,"min":3,"max":5,"median":4,"p90":4.8,"p99":4.98,` +
+			`"stddev":1.4142135623730951,"ci95_low":-8.706,"ci95_high":16.706,"ratio":1}` + "\n" +
+			`{"session":"b","count":0,"mean":0,"min":0,"max":0,"median":0,"p90":0,"p99":0,` +
+			`"stddev":0,"ci95_low":0,"ci95_high":0,"ratio":0}` + "\n"},
+		{reportText, "Labyrinth solved 2 times with an avg of 4.0 steps\n" +
+			"  min 3, median 4.0, p90 4.8, p99 5.0, max 5\n" +
+			"  stddev 1.4, 95% CI of mean [-8.7, 16.7]\n" +
+			"  1.00 times the shortest path on average\n" +
+			"Labyrinth solved 0 times with an avg of 0.0 steps\n"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		p, err := newReportSink(&buf, tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range results {
+			if err := p.Flush(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+
+	if _, err := newReportSink(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("an unknown format should be an error")
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	m := createUshapedMaze()
+	_ = m.SetStartPoint(1, 0)
+	_ = m.SetTreasure(0, 1)
+
+	if got := m.shortestPath(); got != 2 {
+		t.Errorf("got %d; want 2", got)
+	}
+}
@@ -0,0 +1,539 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/skatsuta/labyrinth/history"
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/spf13/viper"
+)
+
+// defaultSolver is the name recorded for a solver which does not tell its name.
+const defaultSolver = "anonymous"
+
+// session is a series of labyrinths solved by a single Icarus client.
+type session struct {
+	mu     sync.Mutex
+	id     string
+	solver string
+	maze   *Maze
+	// started is when the current maze was created.
+	started time.Time
+	scores  []int
+	// shortest holds the lengths of the shortest paths of the mazes in scores.
+	shortest []int
+	runs     []history.Run
+	// steps is the move log of the current maze.
+	steps []mazelib.Step
+	// keepReplays tells whether the move logs of the mazes are kept in replays.
+	keepReplays bool
+	replays     []mazelib.Replay
+	// trace is the trace of the current maze, recorded only if traced is set.
+	trace mazelib.Trace
+	// traced receives the trace of each maze as soon as the maze ends
+	// along with the number of the maze in the session.
+	traced func(n int, t mazelib.Trace)
+	// active is when Icarus last acted in the session. It is guarded by the mu of the store.
+	active time.Time
+}
+
+// start replaces the current maze with m, abandoning the current maze if it is unsolved.
+// The caller must hold s.mu.
+func (s *session) start(m *Maze) {
+	if s.maze != nil && !s.maze.solved() {
+		s.runs = append(s.runs, s.run(history.Abandoned))
+		if s.keepReplays {
+			s.replays = append(s.replays, s.replay())
+		}
+		s.endTrace()
+	}
+	s.maze = m
+	s.started = time.Now()
+	s.steps = nil
+	if s.traced != nil {
+		s.trace = mazelib.Trace{Solver: s.solver, Seed: m.seed, Maze: m.data(), Started: s.started}
+	}
+}
+
+// endTrace passes the trace of the current maze, which has just ended, to s.traced.
+// The caller must hold s.mu.
+func (s *session) endTrace() {
+	if s.traced != nil {
+		s.traced(len(s.runs), s.trace)
+	}
+}
+
+// path returns the rooms Icarus has gone through in the current maze.
+func (s *session) path() []mazelib.Coordinate {
+	if s.maze == nil {
+		return nil
+	}
+	path := []mazelib.Coordinate{s.maze.start}
+	for _, st := range s.steps {
+		if !st.Blocked {
+			path = append(path, st.To)
+		}
+	}
+	return path
+}
+
+// replay returns the replay of the current maze.
+// The caller must hold s.mu.
+func (s *session) replay() mazelib.Replay {
+	steps := make([]mazelib.Step, len(s.steps))
+	copy(steps, s.steps)
+	return mazelib.Replay{Maze: s.maze.data(), Steps: steps}
+}
+
+// run returns the record of the current maze ending with outcome.
+// The caller must hold s.mu.
+func (s *session) run(outcome history.Outcome) history.Run {
+	solver := s.solver
+	if solver == "" {
+		solver = defaultSolver
+	}
+	return history.Run{
+		Solver:    solver,
+		Seed:      s.maze.seed,
+		Width:     s.maze.Width(),
+		Height:    s.maze.Height(),
+		Algorithm: s.maze.algorithm,
+		Steps:     s.maze.StepsTaken,
+		Duration:  time.Since(s.started),
+		Outcome:   outcome,
+		Started:   s.started,
+	}
+}
+
+// move moves Icarus one step in direction and returns the reply
+// along with the corresponding HTTP status code.
+// The move is recorded in the trace of the current maze if it is traced.
+// The caller must hold s.mu.
+func (s *session) move(direction string) (mazelib.Reply, int) {
+	r, code := s.step(direction)
+	if s.maze == nil || s.traced == nil {
+		return r, code
+	}
+
+	s.trace.Moves = append(s.trace.Moves, mazelib.TraceMove{Direction: direction, Reply: r, Time: time.Now()})
+	if r.Victory {
+		s.endTrace()
+	}
+	return r, code
+}
+
+// step moves Icarus one step in direction and returns the reply
+// along with the corresponding HTTP status code.
+// The caller must hold s.mu.
+func (s *session) step(direction string) (mazelib.Reply, int) {
+	m := s.maze
+	if m == nil {
+		return errorReply(mazelib.ErrNoSession)
+	}
+
+	var (
+		err error
+		dir mazelib.Direction
+	)
+
+	switch direction {
+	case "left":
+		dir, err = mazelib.W, m.MoveLeft()
+	case "right":
+		dir, err = mazelib.E, m.MoveRight()
+	case "down":
+		dir, err = mazelib.S, m.MoveDown()
+	case "up":
+		dir, err = mazelib.N, m.MoveUp()
+	default:
+		err = mazelib.ErrBadDirection
+	}
+
+	// log the moves and the bumps into walls
+	if err == nil || err == mazelib.ErrWall {
+		s.steps = append(s.steps, mazelib.Step{Direction: dir, To: m.icarus, Blocked: err != nil})
+	}
+
+	if err == mazelib.ErrVictory {
+		// Icarus has already reached the treasure.
+		err = mazelib.ErrFinished
+	}
+	if err != nil {
+		return errorReply(err)
+	}
+
+	var r mazelib.Reply
+
+	sv, e := m.LookAround()
+
+	if e != nil {
+		if e == mazelib.ErrVictory {
+			s.scores = append(s.scores, m.StepsTaken)
+			s.shortest = append(s.shortest, m.shortestPath())
+			s.runs = append(s.runs, s.run(history.Victory))
+			if s.keepReplays {
+				s.replays = append(s.replays, s.replay())
+			}
+			r.Victory = true
+			r.Message = fmt.Sprintf("Victory achieved in %d steps \n", m.StepsTaken)
+		} else {
+			return errorReply(e)
+		}
+	}
+
+	r.Survey = sv
+	return r, http.StatusOK
+}
+
+// results returns the results of the session, which has ended.
+// The maze in progress, if any, is recorded as abandoned and its trace ends.
+func (s *session) results() Results {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scores := make([]int, len(s.scores))
+	copy(scores, s.scores)
+	shortest := make([]int, len(s.shortest))
+	copy(shortest, s.shortest)
+	runs := make([]history.Run, len(s.runs), len(s.runs)+1)
+	copy(runs, s.runs)
+	replays := make([]mazelib.Replay, len(s.replays), len(s.replays)+1)
+	copy(replays, s.replays)
+	if s.maze != nil && !s.maze.solved() {
+		runs = append(runs, s.run(history.Abandoned))
+		if s.keepReplays {
+			replays = append(replays, s.replay())
+		}
+		if s.traced != nil {
+			s.traced(len(runs), s.trace)
+		}
+	}
+	return Results{Session: s.id, Scores: scores, Shortest: shortest, Runs: runs, Replays: replays}
+}
+
+// sessionStore holds active sessions keyed by their IDs.
+// It is safe for concurrent use.
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	// last is the ID of the session created last.
+	last string
+	// replays tells whether the sessions keep the move logs of their mazes.
+	replays bool
+	// traced, if set, receives the trace of each maze of the sessions as soon as it ends.
+	traced func(id string, n int, t mazelib.Trace)
+}
+
+// newSessionStore returns a new empty sessionStore.
+func newSessionStore() *sessionStore {
+	return &sessionStore{
+		sessions: make(map[string]*session),
+	}
+}
+
+// create creates a new session and registers it to the store.
+func (st *sessionStore) create() (*session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &session{id: id, keepReplays: st.replays, active: time.Now()}
+	if st.traced != nil {
+		s.traced = func(n int, t mazelib.Trace) {
+			st.traced(id, n, t)
+		}
+	}
+
+	st.mu.Lock()
+	st.sessions[id] = s
+	st.last = id
+	st.mu.Unlock()
+
+	return s, nil
+}
+
+// get returns the session identified by id.
+func (st *sessionStore) get(id string) (*session, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	s, found := st.sessions[id]
+	return s, found
+}
+
+// use returns the session identified by id and marks it active.
+func (st *sessionStore) use(id string) (*session, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	s, found := st.sessions[id]
+	if found {
+		s.active = time.Now()
+	}
+	return s, found
+}
+
+// latest returns the session created last if it is still active.
+func (st *sessionStore) latest() (*session, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	s, found := st.sessions[st.last]
+	return s, found
+}
+
+// all returns the active sessions sorted by their IDs.
+func (st *sessionStore) all() []*session {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	list := make([]*session, 0, len(st.sessions))
+	for _, s := range st.sessions {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].id < list[j].id })
+	return list
+}
+
+// remove unregisters the session identified by id from the store and returns it.
+func (st *sessionStore) remove(id string) (*session, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	s, found := st.sessions[id]
+	delete(st.sessions, id)
+	return s, found
+}
+
+// drain unregisters all the sessions from the store and returns them.
+func (st *sessionStore) drain() []*session {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	list := make([]*session, 0, len(st.sessions))
+	for id, s := range st.sessions {
+		list = append(list, s)
+		delete(st.sessions, id)
+	}
+	return list
+}
+
+// expire unregisters the sessions inactive since before and returns them.
+func (st *sessionStore) expire(before time.Time) []*session {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	var list []*session
+	for id, s := range st.sessions {
+		if s.active.Before(before) {
+			list = append(list, s)
+			delete(st.sessions, id)
+		}
+	}
+	return list
+}
+
+// newSessionID generates a random session ID.
+func newSessionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Results is the outcome of a session.
+type Results struct {
+	Session string
+	Scores  []int
+	// Shortest holds the lengths of the shortest paths of the mazes in Scores.
+	Shortest []int
+	// Runs is the record of every maze played in the session.
+	Runs []history.Run
+	// Replays holds the move logs of the mazes in Runs
+	// if the sink of the server saves them.
+	Replays []mazelib.Replay
+}
+
+// ResultsSink receives the final results of sessions when they end.
+type ResultsSink interface {
+	Flush(r Results) error
+}
+
+// TraceSink receives the trace of each maze as soon as the maze ends.
+// A ResultsSink which is also a TraceSink turns tracing on in the server.
+type TraceSink interface {
+	// Trace receives the trace of the nth maze of session.
+	Trace(session string, n int, t mazelib.Trace) error
+}
+
+// historySink is a ResultsSink which adds the runs to the history stored in a file.
+// The file is opened only while flushing so that others can read it in the meantime.
+type historySink struct {
+	path string
+}
+
+// Flush adds the runs of r to the history.
+func (h historySink) Flush(r Results) error {
+	if len(r.Runs) == 0 {
+		return nil
+	}
+
+	st, err := history.Open(h.path)
+	if err != nil {
+		return err
+	}
+	if err := st.Add(r.Runs...); err != nil {
+		_ = st.Close()
+		return err
+	}
+	return st.Close()
+}
+
+// gifSink is a ResultsSink which saves the replays as GIF images in dir,
+// named after the session and the number of the maze in it.
+type gifSink struct {
+	dir string
+}
+
+// Flush saves the replays of r.
+func (g gifSink) Flush(r Results) error {
+	if err := os.MkdirAll(g.dir, 0755); err != nil {
+		return err
+	}
+
+	for i, rp := range r.Replays {
+		name := filepath.Join(g.dir, fmt.Sprintf("%s-%d.gif", r.Session, i+1))
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		if err := mazelib.RenderGIF(f, rp, mazelib.GIFOptions{}); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// savesReplays reports whether sink saves the replays of results,
+// which sessions keep only if it does.
+func savesReplays(sink ResultsSink) bool {
+	switch s := sink.(type) {
+	case gifSink:
+		return true
+	case multiSink:
+		for _, sub := range s {
+			if savesReplays(sub) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// traceSinks returns the TraceSinks in sink.
+func traceSinks(sink ResultsSink) []TraceSink {
+	switch s := sink.(type) {
+	case TraceSink:
+		return []TraceSink{s}
+	case multiSink:
+		var list []TraceSink
+		for _, sub := range s {
+			list = append(list, traceSinks(sub)...)
+		}
+		return list
+	}
+	return nil
+}
+
+// traceSink is a TraceSink which saves the traces as JSON files in dir,
+// named after the session and the number of the maze in it.
+type traceSink struct {
+	dir string
+}
+
+// Flush does nothing as the traces have been saved as their mazes ended.
+func (t traceSink) Flush(r Results) error {
+	return nil
+}
+
+// Trace saves tr, the trace of the nth maze of session.
+func (t traceSink) Trace(session string, n int, tr mazelib.Trace) error {
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return err
+	}
+
+	b, err := mazelib.MarshalTrace(tr)
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(t.dir, fmt.Sprintf("%s-%d.json", session, n))
+	return os.WriteFile(name, append(b, '\n'), 0644)
+}
+
+// multiSink is a ResultsSink which flushes results to all of its sinks.
+type multiSink []ResultsSink
+
+// Flush flushes r to every sink and returns the first error.
+func (m multiSink) Flush(r Results) error {
+	var first error
+	for _, s := range m {
+		if err := s.Flush(r); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// resultsSink returns the sink of the results of sessions configured by the flags:
+// they are reported to w in the format given by --report,
+// stored in the history file given by --history if any,
+// replayed as GIF images in the directory given by --gif-dir if any,
+// and traced in the directory given by --trace-dir if any.
+func resultsSink(w io.Writer) (ResultsSink, error) {
+	rs, err := newReportSink(w, viper.GetString("report"))
+	if err != nil {
+		return nil, err
+	}
+
+	sinks := multiSink{rs}
+	if path := viper.GetString("history"); path != "" {
+		sinks = append(sinks, historySink{path: path})
+	}
+	if dir := viper.GetString("gif-dir"); dir != "" {
+		sinks = append(sinks, gifSink{dir: dir})
+	}
+	if dir := viper.GetString("trace-dir"); dir != "" {
+		sinks = append(sinks, traceSink{dir: dir})
+	}
+	return sinks, nil
+}
@@ -0,0 +1,211 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// Defining the spectate command.
+// This will be called as 'laybrinth spectate'
+var spectateCmd = &cobra.Command{
+	Use:   "spectate [session]",
+	Short: "Watch Icarus in a laybrinth on a Daedalus server",
+	Long: `Spectate shows what Icarus has revealed so far of his laybrinth in
+the session on the Daedalus server at --server-url, or in the session
+started last if session is omitted. The rooms he has not visited yet are
+hidden in the fog, and the others show how many times he has entered them,
+so it is easy to see where he wastes steps.
+
+The view is refreshed every --interval until the session ends.
+The same view is served as text by the /spectate address with
+format=text, e.g. /spectate?session=...&format=text&style=ascii.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		name, _ := cmd.Flags().GetString("style")
+		style, ok := mazelib.Styles[name]
+		if !ok {
+			return fmt.Errorf("unknown maze style %q", name)
+		}
+		r := mazelib.Renderer{Style: style, Color: viper.GetBool("color")}
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+
+		var id string
+		if len(args) > 0 {
+			id = args[0]
+		}
+		ctx := cmd.Context()
+		for seen := false; ; seen = true {
+			sg, err := c.Spectate(ctx, id)
+			if errors.Is(err, mazelib.ErrNoSession) && seen {
+				fmt.Println("The session has ended.")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			// keep watching the same session
+			id = sg.Session
+
+			if !once {
+				fmt.Print(clearScreen)
+			}
+			if err := writeSighting(os.Stdout, r, sg); err != nil {
+				return err
+			}
+			if once {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(interval):
+			}
+		}
+	},
+}
+
+func init() {
+	spectateCmd.Flags().String("style", "box", "characters the laybrinth is drawn in: ascii or box")
+	spectateCmd.Flags().Duration("interval", 500*time.Millisecond, "how often the view is refreshed")
+	spectateCmd.Flags().Bool("once", false, "shows the view once and exits")
+	RootCmd.AddCommand(spectateCmd)
+}
+
+// writeSighting writes sg drawn by r to w along with how Icarus is doing.
+func writeSighting(w io.Writer, r mazelib.Renderer, sg mazelib.Sighting) error {
+	status := "searching"
+	if sg.Victory {
+		status = "found the treasure"
+	}
+	if _, err := fmt.Fprintf(w, "Session %s: %s after %d steps, %d of them back to rooms visited before\n",
+		sg.Session, status, sg.Steps, sg.Wasted()); err != nil {
+		return err
+	}
+	return r.RenderSighting(w, sg)
+}
+
+// Spectate returns the API response to the /spectate address: what Icarus
+// has revealed so far of his maze in the session given by the session
+// parameter, or in the session started last if it is omitted.
+// With format=text, the maze is drawn as text in the characters given by
+// the style parameter instead.
+func (s *server) Spectate(c *gin.Context) {
+	sg, code := s.spectate(c.Query("session"))
+	if c.Query("format") != "text" {
+		c.JSON(code, sg)
+		return
+	}
+	if sg.Error {
+		c.String(code, "%s\n", sg.Message)
+		return
+	}
+
+	style, ok := mazelib.Styles[c.DefaultQuery("style", "box")]
+	if !ok {
+		c.String(http.StatusBadRequest, "unknown maze style %q\n", c.Query("style"))
+		return
+	}
+	var b strings.Builder
+	if err := writeSighting(&b, mazelib.Renderer{Style: style}, sg); err != nil {
+		c.String(http.StatusInternalServerError, "%v\n", err)
+		return
+	}
+	c.String(code, "%s", b.String())
+}
+
+// spectate returns what Icarus has revealed so far of his maze in the session
+// identified by id, or in the session created last if id is empty,
+// along with the corresponding HTTP status code.
+func (s *server) spectate(id string) (mazelib.Sighting, int) {
+	var (
+		sess  *session
+		found bool
+	)
+	if id == "" {
+		sess, found = s.sessions.latest()
+	} else {
+		sess, found = s.sessions.get(id)
+	}
+	if !found {
+		r, code := errorReply(mazelib.ErrNoSession)
+		return mazelib.Sighting{Reply: r}, code
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	return sess.sighting(false), http.StatusOK
+}
+
+// sighting returns what Icarus has revealed so far of the maze of s,
+// or the whole maze if reveal is true.
+// The caller must hold s.mu.
+func (s *session) sighting(reveal bool) mazelib.Sighting {
+	var sg mazelib.Sighting
+	if s.maze != nil {
+		sg = s.maze.sighting(reveal)
+	}
+	sg.Session, sg.Solver = s.id, s.solver
+	return sg
+}
+
+// sighting returns what Icarus has revealed of m: the rooms he has visited,
+// or all the rooms if reveal is true.
+func (m *Maze) sighting(reveal bool) mazelib.Sighting {
+	sg := mazelib.Sighting{
+		Width:  m.Width(),
+		Height: m.Height(),
+		Icarus: m.icarus,
+		Steps:  m.StepsTaken,
+		Rooms:  make([][]*mazelib.Sight, m.Height()),
+	}
+	sg.Victory = m.solved()
+	for y, row := range m.rooms {
+		sg.Rooms[y] = make([]*mazelib.Sight, len(row))
+		for x, r := range row {
+			if r.Visited || reveal {
+				sg.Rooms[y][x] = &mazelib.Sight{
+					Walls:    r.Walls,
+					Visits:   m.visits[y][x],
+					Start:    r.Start,
+					Treasure: r.Treasure,
+				}
+			}
+		}
+	}
+	return sg
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+func TestSpectate(t *testing.T) {
+	s := newServer(&recordSink{})
+	s.out = io.Discard
+	h := s.handler()
+
+	_, r := serve(t, h, "/awake")
+	sess, _ := s.sessions.get(r.Session)
+	sess.maze = createUshapedMaze()
+	_ = sess.maze.SetStartPoint(0, 0)
+	_ = sess.maze.SetTreasure(0, 1)
+	for _, dir := range []string{"right", "left", "right", "down"} {
+		serve(t, h, "/move/"+dir+"?session="+r.Session)
+	}
+
+	spectate := func(query string) (int, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/spectate"+query, nil))
+		return w.Code, w.Body.String()
+	}
+
+	// the session started last is spectated if none is given
+	for _, query := range []string{"?session=" + r.Session, ""} {
+		code, body := spectate(query)
+		var sg mazelib.Sighting
+		if err := json.Unmarshal([]byte(body), &sg); err != nil || code != http.StatusOK {
+			t.Fatalf("%q: got status %d and %q: %v", query, code, body, err)
+		}
+		if sg.Session != r.Session || sg.Steps != 4 || sg.Icarus != (mazelib.Coordinate{X: 1, Y: 1}) {
+			t.Errorf("%q: got %+v", query, sg)
+		}
+		if got, want := sg.Visits(), [][]int{{2, 2}, {0, 1}}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got visits %v; want %v", query, got, want)
+		}
+		// the treasure has not been found yet
+		if sg.Rooms[1][0] != nil || !sg.Rooms[0][0].Start {
+			t.Errorf("%q: got rooms %+v", query, sg.Rooms)
+		}
+	}
+
+	code, body := spectate("?format=text&style=ascii")
+	want := `Session ` + r.Session + `: searching after 4 steps, 2 of them back to rooms visited before
++---+---+
+| S   2 |
++---+   +
+####  @ |
+####+---+
+`
+	if code != http.StatusOK || body != want {
+		t.Errorf("got status %d and\n%s\nwant\n%s", code, body, want)
+	}
+
+	if code, _ := spectate("?session=nope"); code != http.StatusNotFound {
+		t.Errorf("got status %d for an unknown session; want %d", code, http.StatusNotFound)
+	}
+	if code, body := spectate("?format=text&style=fancy"); code != http.StatusBadRequest || !strings.Contains(body, "fancy") {
+		t.Errorf("got status %d and %q for an unknown style", code, body)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skatsuta/labyrinth/client"
+	"github.com/skatsuta/labyrinth/mazelib"
+	"github.com/spf13/viper"
+)
+
+// Names of the transports Icarus can use to talk to Daedalus.
+const (
+	transportLocal     = "local"
+	transportHTTP      = "http"
+	transportWebSocket = "websocket"
+)
+
+// pollInterval is the interval at which Icarus polls Daedalus until it gets ready.
+const pollInterval = 100 * time.Millisecond
+
+// transport carries the requests of Icarus to Daedalus.
+// Error replies are returned as errors which match the sentinel errors in mazelib.
+// *client.Client is the transport over HTTP.
+type transport interface {
+	// Awake asks Daedalus for a new maze and returns the reply
+	// describing Icarus's awakening location.
+	Awake(ctx context.Context) (mazelib.Reply, error)
+	// Move moves Icarus one step in dir.
+	Move(ctx context.Context, dir mazelib.Direction) (mazelib.Reply, error)
+	// Done tells Daedalus that Icarus has solved mazes as many times as he wants.
+	Done(ctx context.Context) (mazelib.Reply, error)
+}
+
+// newTransport returns the transport to a remote server specified by name.
+// If the transport holds resources, it also implements io.Closer.
+func newTransport(name string) (transport, error) {
+	switch name {
+	case transportLocal:
+		return nil, errors.New("the local transport is available only when Daedalus and Icarus run in one process")
+	case transportHTTP, "":
+		return newClient()
+	case transportWebSocket, "ws":
+		base, err := serverURL()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig, err := clientTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		u := *base
+		u.Scheme = "ws"
+		if base.Scheme == "https" {
+			u.Scheme = "wss"
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/play"
+		t, err := dialWebSocket(u.String(), tlsConfig, viper.GetString("token"))
+		if err != nil {
+			return nil, err
+		}
+		t.solver = viper.GetString("solver")
+		return t, nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q", name)
+	}
+}
+
+// newClient returns a client of the Daedalus server Icarus connects to.
+func newClient() (*client.Client, error) {
+	base, err := serverURL()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := clientTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	hc := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+	return client.New(base.String(),
+		client.WithHTTPClient(hc),
+		client.WithToken(viper.GetString("token")),
+		client.WithSolver(viper.GetString("solver")),
+	), nil
+}
+
+// waitForServer polls the Daedalus server Icarus connects to
+// until it gets ready, ctx is done, or the time given by --wait elapses.
+func waitForServer(ctx context.Context) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, viper.GetDuration("wait"))
+	defer cancel()
+	return c.WaitReady(ctx, pollInterval)
+}
+
+// serverURL returns the base URL of the Daedalus server Icarus connects to.
+// Unless --server-url is given, it is the local server at --host and --port,
+// over HTTPS if TLS is enabled with --tls-cert.
+func serverURL() (*url.URL, error) {
+	if s := viper.GetString("server-url"); s != "" {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("unsupported scheme of the server URL: %q", s)
+		}
+		return u, nil
+	}
+
+	host := viper.GetString("host")
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	u := &url.URL{Scheme: "http", Host: net.JoinHostPort(host, viper.GetString("port"))}
+	if viper.GetString("tls-cert") != "" {
+		u.Scheme = "https"
+	}
+	return u, nil
+}
+
+// clientTLSConfig returns the TLS configuration of Icarus.
+// It trusts the certificates in --tls-ca, or in --tls-cert if --tls-ca is not given,
+// so that a self-signed certificate of Daedalus can be verified.
+// It returns nil to use the system roots if neither is given.
+func clientTLSConfig() (*tls.Config, error) {
+	file := viper.GetString("tls-ca")
+	if file == "" {
+		file = viper.GetString("tls-cert")
+	}
+	if file == "" {
+		return nil, nil
+	}
+
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+// localTransport is a transport to a server in the same process.
+// It plays on the maze directly instead of over the network,
+// with the same replies as the HTTP handlers.
+type localTransport struct {
+	s       *server
+	solver  string
+	session string
+}
+
+func (t *localTransport) Awake(ctx context.Context) (mazelib.Reply, error) {
+	if err := ctx.Err(); err != nil {
+		return mazelib.Reply{}, err
+	}
+
+	r, _ := t.s.awake(t.session, t.solver)
+	if err := r.Err(); err != nil {
+		return r, err
+	}
+	t.session = r.Session
+	return r, nil
+}
+
+func (t *localTransport) Move(ctx context.Context, dir mazelib.Direction) (mazelib.Reply, error) {
+	if err := ctx.Err(); err != nil {
+		return mazelib.Reply{}, err
+	}
+
+	r, _ := t.s.move(t.session, dir.String())
+	return r, r.Err()
+}
+
+func (t *localTransport) Done(ctx context.Context) (mazelib.Reply, error) {
+	if err := ctx.Err(); err != nil {
+		return mazelib.Reply{}, err
+	}
+
+	r, _ := t.s.end(t.session)
+	t.session = ""
+	return r, r.Err()
+}
@@ -0,0 +1,135 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package commands
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+var upgrader = websocket.Upgrader{}
+
+// Play returns the API response to the /play address.
+// It upgrades the connection to a WebSocket, over which Icarus streams
+// mazelib.Command frames and Daedalus answers each of them with a mazelib.Reply frame.
+// The connection plays a single session, which ends when the connection is closed.
+func (s *server) Play(c *gin.Context) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		s.logger.Errorf("error upgrading to WebSocket: %v\n", err)
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	id := c.Query("session")
+	defer func() {
+		if id != "" {
+			_, _ = s.end(id)
+		}
+	}()
+
+	for {
+		var cmd mazelib.Command
+		if err := conn.ReadJSON(&cmd); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.logger.Debugf("error reading a command: %v\n", err)
+			}
+			return
+		}
+
+		if err := conn.WriteJSON(s.exec(&id, cmd)); err != nil {
+			s.logger.Debugf("error writing a reply: %v\n", err)
+			return
+		}
+	}
+}
+
+// wsTransport is a transport which streams commands to Daedalus over a WebSocket.
+type wsTransport struct {
+	conn *websocket.Conn
+	// solver is the name Icarus tells Daedalus on awake.
+	solver string
+}
+
+// dialWebSocket connects to the Daedalus WebSocket endpoint at url.
+// tlsConfig may be nil, and token is sent as a bearer token unless it is empty.
+func dialWebSocket(url string, tlsConfig *tls.Config, token string) (*wsTransport, error) {
+	d := *websocket.DefaultDialer
+	d.TLSClientConfig = tlsConfig
+
+	header := make(http.Header)
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	conn, _, err := d.Dial(url, header)
+	if err != nil {
+		return nil, err
+	}
+	return &wsTransport{conn: conn}, nil
+}
+
+// roundTrip sends cmd and waits for the reply to it until ctx is done.
+func (t *wsTransport) roundTrip(ctx context.Context, cmd mazelib.Command) (mazelib.Reply, error) {
+	var r mazelib.Reply
+
+	deadline, _ := ctx.Deadline()
+	if err := t.conn.NetConn().SetDeadline(deadline); err != nil {
+		return r, err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		// unblock the pending read or write
+		_ = t.conn.NetConn().SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := t.conn.WriteJSON(cmd); err != nil {
+		return r, err
+	}
+	if err := t.conn.ReadJSON(&r); err != nil {
+		if ctx.Err() != nil {
+			return r, ctx.Err()
+		}
+		return r, err
+	}
+	return r, r.Err()
+}
+
+func (t *wsTransport) Awake(ctx context.Context) (mazelib.Reply, error) {
+	return t.roundTrip(ctx, mazelib.Command{Action: mazelib.ActionAwake, Solver: t.solver})
+}
+
+func (t *wsTransport) Move(ctx context.Context, dir mazelib.Direction) (mazelib.Reply, error) {
+	return t.roundTrip(ctx, mazelib.Command{Action: mazelib.ActionMove, Direction: dir.String()})
+}
+
+func (t *wsTransport) Done(ctx context.Context) (mazelib.Reply, error) {
+	return t.roundTrip(ctx, mazelib.Command{Action: mazelib.ActionDone})
+}
+
+func (t *wsTransport) Close() error {
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = t.conn.WriteMessage(websocket.CloseMessage, msg)
+	return t.conn.Close()
+}
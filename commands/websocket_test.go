@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/skatsuta/labyrinth/mazelib"
+)
+
+func TestPlayOverWebSocket(t *testing.T) {
+	sink := &recordSink{}
+	ts := httptest.NewServer(newServer(sink).handler())
+	defer ts.Close()
+
+	tr, err := dialWebSocket("ws"+strings.TrimPrefix(ts.URL, "http")+"/play", nil, "")
+	if err != nil {
+		t.Fatalf("error dialing: %v", err)
+	}
+	defer tr.Close()
+
+	ctx := context.Background()
+
+	r, err := tr.Awake(ctx)
+	if err != nil || r.Session == "" {
+		t.Fatalf("awake: got %+v, %v", r, err)
+	}
+
+	if _, err := tr.Move(ctx, 0); !errors.Is(err, mazelib.ErrBadDirection) {
+		t.Errorf("move in no direction: got %v; want %v", err, mazelib.ErrBadDirection)
+	}
+
+	if _, err := tr.Done(ctx); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if len(sink.results) != 1 || sink.results[0].Session != r.Session {
+		t.Errorf("results of %q should be flushed once, but got %v", r.Session, sink.results)
+	}
+}
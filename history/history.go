@@ -0,0 +1,205 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+// Package history stores the history of runs in a local bbolt file
+// and ranks solvers on a leaderboard.
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Outcome is how a run ended.
+type Outcome string
+
+// Outcomes of a run.
+const (
+	// Victory means the solver found the treasure.
+	Victory Outcome = "victory"
+	// Abandoned means the solver gave up the maze before finding the treasure.
+	Abandoned Outcome = "abandoned"
+)
+
+// Run is a record of an attempt of a solver to solve a maze.
+type Run struct {
+	Solver    string        `json:"solver"`
+	Seed      int64         `json:"seed"`
+	Width     int           `json:"width"`
+	Height    int           `json:"height"`
+	Algorithm string        `json:"algorithm"`
+	Steps     int           `json:"steps"`
+	Duration  time.Duration `json:"duration"`
+	Outcome   Outcome       `json:"outcome"`
+	Started   time.Time     `json:"started"`
+}
+
+// Class returns the class of the maze of r, which consists of
+// the generation algorithm and the dimensions.
+func (r Run) Class() string {
+	return fmt.Sprintf("%s %dx%d", r.Algorithm, r.Width, r.Height)
+}
+
+var bucketRuns = []byte("runs")
+
+// Store is a history of runs stored in a bbolt file.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the store in the file at path, creating it if it does not exist.
+// The file is locked until the store is closed.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add appends runs to the store.
+func (s *Store) Add(runs ...Run) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucketRuns)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range runs {
+			v, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			key := make([]byte, 8)
+			binary.BigEndian.PutUint64(key, seq)
+			if err := b.Put(key, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Runs returns all the runs in the store in the order they were added.
+func (s *Store) Runs() ([]Run, error) {
+	var runs []Run
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketRuns)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, v []byte) error {
+			var r Run
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			runs = append(runs, r)
+			return nil
+		})
+	})
+	return runs, err
+}
+
+// Ranking is the record of a solver in a class of mazes.
+type Ranking struct {
+	Class     string
+	Solver    string
+	Runs      int
+	Victories int
+	// MeanSteps and BestSteps are taken over victories.
+	MeanSteps float64
+	BestSteps int
+	// MeanDuration is taken over victories.
+	MeanDuration time.Duration
+}
+
+// WinRate returns the ratio of victories to runs.
+func (r Ranking) WinRate() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(r.Victories) / float64(r.Runs)
+}
+
+// Rank aggregates runs by class and solver. The rankings are sorted by class,
+// and then from the best solver to the worst: fewer mean steps first,
+// solvers without any victory last.
+func Rank(runs []Run) []Ranking {
+	type key struct{ class, solver string }
+
+	idx := make(map[key]int)
+	var list []Ranking
+	totalSteps := make(map[key]int)
+	totalDur := make(map[key]time.Duration)
+
+	for _, run := range runs {
+		k := key{run.Class(), run.Solver}
+		i, found := idx[k]
+		if !found {
+			i = len(list)
+			idx[k] = i
+			list = append(list, Ranking{Class: k.class, Solver: k.solver})
+		}
+
+		rk := &list[i]
+		rk.Runs++
+		if run.Outcome != Victory {
+			continue
+		}
+		rk.Victories++
+		if rk.Victories == 1 || run.Steps < rk.BestSteps {
+			rk.BestSteps = run.Steps
+		}
+		totalSteps[k] += run.Steps
+		totalDur[k] += run.Duration
+	}
+
+	for k, i := range idx {
+		if v := list[i].Victories; v > 0 {
+			list[i].MeanSteps = float64(totalSteps[k]) / float64(v)
+			list[i].MeanDuration = totalDur[k] / time.Duration(v)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Class != b.Class {
+			return a.Class < b.Class
+		}
+		if (a.Victories == 0) != (b.Victories == 0) {
+			return b.Victories == 0
+		}
+		if a.MeanSteps != b.MeanSteps {
+			return a.MeanSteps < b.MeanSteps
+		}
+		return a.WinRate() > b.WinRate()
+	})
+
+	return list
+}
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	started := time.Date(2015, 10, 1, 0, 0, 0, 0, time.UTC)
+	want := []Run{
+		{Solver: "icarus", Seed: 1, Width: 15, Height: 10, Algorithm: "a", Steps: 42, Duration: time.Second, Outcome: Victory, Started: started},
+		{Solver: "icarus", Seed: 2, Width: 15, Height: 10, Algorithm: "a", Steps: 7, Outcome: Abandoned, Started: started},
+		{Solver: "minos", Seed: 3, Width: 5, Height: 5, Algorithm: "a", Steps: 9, Outcome: Victory, Started: started},
+	}
+
+	// add the runs across two openings of the file
+	for _, runs := range [][]Run{want[:2], want[2:]} {
+		st, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := st.Add(runs...); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	st, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	got, err := st.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+}
+
+func TestRank(t *testing.T) {
+	run := func(solver string, w int, steps int, outcome Outcome) Run {
+		return Run{Solver: solver, Width: w, Height: w, Algorithm: "a", Steps: steps, Duration: time.Duration(steps) * time.Second, Outcome: outcome}
+	}
+	runs := []Run{
+		run("slow", 5, 30, Victory),
+		run("loser", 5, 99, Abandoned),
+		run("fast", 5, 10, Victory),
+		run("fast", 5, 20, Victory),
+		run("slow", 5, 10, Abandoned),
+		run("fast", 3, 4, Victory),
+	}
+
+	want := []Ranking{
+		{Class: "a 3x3", Solver: "fast", Runs: 1, Victories: 1, MeanSteps: 4, BestSteps: 4, MeanDuration: 4 * time.Second},
+		{Class: "a 5x5", Solver: "fast", Runs: 2, Victories: 2, MeanSteps: 15, BestSteps: 10, MeanDuration: 15 * time.Second},
+		{Class: "a 5x5", Solver: "slow", Runs: 2, Victories: 1, MeanSteps: 30, BestSteps: 30, MeanDuration: 30 * time.Second},
+		{Class: "a 5x5", Solver: "loser", Runs: 1},
+	}
+
+	if got := Rank(runs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+	if got := want[2].WinRate(); got != 0.5 {
+		t.Errorf("WinRate() = %v; want 0.5", got)
+	}
+}
@@ -1,10 +1,18 @@
+// Package log provides a leveled logger writing records with fields
+// in text or JSON.
 package log
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-
-	"github.com/spf13/viper"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Prefixes in log.
@@ -15,31 +23,217 @@ const (
 	PrefixError = "[ERROR] "
 )
 
-var (
-	debug = false
-	out   = os.Stdout
+// Level is the severity of a record.
+type Level int
+
+// Levels in order of severity.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
 )
 
-// Debugf formats according to a format specifier with PrefixDebug string.
-func Debugf(format string, a ...interface{}) {
-	if !viper.GetBool("debug") {
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+var levelPrefixes = map[Level]string{
+	LevelDebug: PrefixDebug,
+	LevelInfo:  PrefixInfo,
+	LevelWarn:  PrefixWarn,
+	LevelError: PrefixError,
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel returns the level named s: debug, info, warn or error.
+func ParseLevel(s string) (Level, error) {
+	for l, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q; want debug, info, warn or error", s)
+}
+
+// Options configure a Logger.
+type Options struct {
+	// Level is the least severe level written.
+	Level Level
+	// JSON writes a JSON object per line instead of text.
+	JSON bool
+	// Time prefixes text records with their time. JSON records always have one.
+	Time bool
+}
+
+// Logger writes records of a level and above with its fields to an output.
+// It is safe for concurrent use, and so are the loggers derived from it.
+type Logger struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	opts   Options
+	fields []field
+	now    func() time.Time
+}
+
+// field is a key-value pair attached to records.
+type field struct {
+	key   string
+	value interface{}
+}
+
+// New returns a logger writing to out.
+func New(out io.Writer, opts Options) *Logger {
+	return &Logger{mu: &sync.Mutex{}, out: out, opts: opts, now: time.Now}
+}
+
+// Default returns a logger writing text of info and above to os.Stdout.
+func Default() *Logger {
+	return New(os.Stdout, Options{Level: LevelInfo})
+}
+
+// With returns a logger which adds the fields given as key-value pairs
+// to the fields of l.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	c := *l
+	c.fields = make([]field, len(l.fields), len(l.fields)+(len(kv)+1)/2)
+	copy(c.fields, l.fields)
+	for i := 0; i < len(kv); i += 2 {
+		f := field{key: fmt.Sprint(kv[i])}
+		if i+1 < len(kv) {
+			f.value = kv[i+1]
+		}
+		c.fields = append(c.fields, f)
+	}
+	return &c
+}
+
+// Enabled reports whether l writes records of level.
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.opts.Level
+}
+
+// Debugf formats according to a format specifier and writes a record of LevelDebug.
+func (l *Logger) Debugf(format string, a ...interface{}) {
+	l.logf(LevelDebug, format, a...)
+}
+
+// Infof formats according to a format specifier and writes a record of LevelInfo.
+func (l *Logger) Infof(format string, a ...interface{}) {
+	l.logf(LevelInfo, format, a...)
+}
+
+// Warnf formats according to a format specifier and writes a record of LevelWarn.
+func (l *Logger) Warnf(format string, a ...interface{}) {
+	l.logf(LevelWarn, format, a...)
+}
+
+// Errorf formats according to a format specifier and writes a record of LevelError.
+func (l *Logger) Errorf(format string, a ...interface{}) {
+	l.logf(LevelError, format, a...)
+}
+
+// Close closes the output of l if it is an io.Closer.
+func (l *Logger) Close() error {
+	if c, ok := l.out.(io.Closer); ok && l.out != os.Stdout && l.out != os.Stderr {
+		return c.Close()
+	}
+	return nil
+}
+
+func (l *Logger) logf(level Level, format string, a ...interface{}) {
+	if !l.Enabled(level) {
 		return
 	}
 
-	fmt.Fprintf(out, PrefixDebug+format, a...)
+	// records are lines of their own
+	msg := strings.TrimRight(fmt.Sprintf(format, a...), "\n")
+	var b []byte
+	if l.opts.JSON {
+		b = l.appendJSON(level, msg)
+	} else {
+		b = l.appendText(level, msg)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.out.Write(b)
 }
 
-// Infof formats according to a format specifier with PrefixInfo string.
-func Infof(format string, a ...interface{}) {
-	fmt.Fprintf(out, PrefixInfo+format, a...)
+// appendText formats a record as
+//
+//	[INFO] message key=value key="quoted value"
+func (l *Logger) appendText(level Level, msg string) []byte {
+	var b strings.Builder
+	if l.opts.Time {
+		b.WriteString(l.now().Format(time.RFC3339) + " ")
+	}
+	b.WriteString(levelPrefixes[level] + msg)
+	for _, f := range l.fields {
+		v := fmt.Sprint(f.value)
+		if v == "" || strings.ContainsAny(v, " =\"\n\t") {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(" " + f.key + "=" + v)
+	}
+	b.WriteString("\n")
+	return []byte(b.String())
 }
 
-// Warnf formats according to a format specifier with PrefixWarn string.
-func Warnf(format string, a ...interface{}) {
-	fmt.Fprintf(out, PrefixWarn+format, a...)
+// appendJSON formats a record as a JSON object with the time, level and message
+// followed by the fields in order.
+func (l *Logger) appendJSON(level Level, msg string) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	// messages are not embedded in HTML
+	enc.SetEscapeHTML(false)
+	encode := func(v interface{}) []byte {
+		buf.Reset()
+		if err := enc.Encode(v); err != nil {
+			buf.Reset()
+			_ = enc.Encode(fmt.Sprint(v))
+		}
+		return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	}
+
+	b := []byte("{")
+	add := func(key string, v interface{}) {
+		if len(b) > 1 {
+			b = append(b, ',')
+		}
+		b = append(append(b, encode(key)...), ':')
+		b = append(b, encode(v)...)
+	}
+
+	add("time", l.now().Format(time.RFC3339Nano))
+	add("level", level.String())
+	add("msg", msg)
+	for _, f := range l.fields {
+		add(f.key, f.value)
+	}
+	return append(b, '}', '\n')
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
 }
 
-// Errorf formats according to a format specifier with PrefixError string.
-func Errorf(format string, a ...interface{}) {
-	fmt.Fprintf(out, PrefixError+format, a...)
+// FromContext returns the logger carried by ctx, or Default if there is none.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
 }
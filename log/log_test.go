@@ -0,0 +1,75 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoggerText(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, Options{Level: LevelInfo})
+	sl := l.With("session", "abc", "step", 3)
+
+	sl.Debugf("hidden\n")
+	sl.Infof("moved %s\n", "up")
+	sl.With("coordinate", "1,-2", "note", "two words").Warnf("bumped")
+	l.Errorf("no fields")
+
+	want := `[INFO] moved up session=abc step=3
+[WARN] bumped session=abc step=3 coordinate=1,-2 note="two words"
+[ERROR] no fields
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestLoggerJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, Options{Level: LevelDebug, JSON: true})
+	l.now = func() time.Time { return time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC) }
+
+	l.With("session", "abc", "step", 3).Debugf("current: %d & more", 42)
+
+	if !bytes.Contains(buf.Bytes(), []byte(" & ")) {
+		t.Errorf("messages should not be escaped for HTML: %s", buf.String())
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"time":    "2015-10-01T12:00:00Z",
+		"level":   "debug",
+		"msg":     "current: 42 & more",
+		"session": "abc",
+		"step":    float64(3),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", LevelDebug, false},
+		{"INFO", LevelInfo, false},
+		{"warn", LevelWarn, false},
+		{"error", LevelError, false},
+		{"verbose", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: got %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is a file which is rotated once it would grow beyond a size:
+// the file is renamed with the suffix .1, the previous .1 to .2, and so on,
+// and a new file is started.
+// It is safe for concurrent use.
+type RotatingFile struct {
+	mu   sync.Mutex
+	path string
+	// maxSize is the size in bytes to rotate at, or 0 never to rotate.
+	maxSize int64
+	// maxBackups is how many rotated files are kept.
+	maxBackups int
+	f          *os.File
+	size       int64
+}
+
+// OpenRotatingFile opens the file at path for appending, which is rotated
+// at maxSize bytes keeping maxBackups rotated files.
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f, size, err := openAppend(path)
+	if err != nil {
+		return nil, err
+	}
+	return &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups, f: f, size: size}, nil
+}
+
+// openAppend opens the file at path for appending and returns it with its size.
+func openAppend(path string) (*os.File, int64, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, 0, err
+	}
+	return f, fi.Size(), nil
+}
+
+// Write writes p to the file, rotating it first if p would not fit in it.
+// If the rotation fails, p is still written to the current file, the rotation
+// is tried again at the next write, and its error is returned.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	var rerr error
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		rerr = r.rotate()
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	if err == nil {
+		err = rerr
+	}
+	return n, err
+}
+
+// rotate shifts the backups and starts a new file.
+// The file is renamed while still open, so that it is kept on failure.
+// The caller must hold r.mu.
+func (r *RotatingFile) rotate() error {
+	backup := func(i int) string {
+		return fmt.Sprintf("%s.%d", r.path, i)
+	}
+	if r.maxBackups > 0 {
+		_ = os.Remove(backup(r.maxBackups))
+		for i := r.maxBackups - 1; i >= 1; i-- {
+			if err := os.Rename(backup(i), backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(r.path, backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return err
+	}
+
+	f, size, err := openAppend(r.path)
+	if err != nil {
+		return err
+	}
+	old := r.f
+	r.f, r.size = f, size
+	return old.Close()
+}
+
+// Close closes the file.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
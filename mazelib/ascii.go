@@ -0,0 +1,205 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MarshalASCII returns d drawn in the classic ASCII grid format, e.g.
+//
+//	+---+---+
+//	| S     |
+//	+---+   +
+//	| T     |
+//	+---+---+
+//
+// where S marks the start and T marks the treasure.
+func MarshalASCII(d MazeData) []byte {
+	return []byte(Renderer{Style: StyleASCII}.draw(dataView(d, d.Start)))
+}
+
+// UnmarshalASCII parses a maze drawn in the classic ASCII grid format.
+// The rooms may be of any width as long as all of them are equally wide,
+// and any character other than a space makes a wall.
+// A room containing S is the start, and a room containing T is the treasure.
+func UnmarshalASCII(b []byte) (MazeData, error) {
+	lines := textLines(b)
+	if len(lines) < 3 || len(lines)%2 == 0 {
+		return MazeData{}, fmt.Errorf("mazelib: %d lines of an ASCII maze; want an odd number of at least 3", len(lines))
+	}
+
+	first := lines[0]
+	cw := -1
+	for i := 1; i < len(first); i++ {
+		if first[i] == '+' {
+			cw = i - 1
+			break
+		}
+	}
+	if first[0] != '+' || cw < 1 || (len(first)-1)%(cw+1) != 0 {
+		return MazeData{}, errors.New("mazelib: the first line of an ASCII maze must be like +---+---+")
+	}
+
+	d := MazeData{
+		Version: MazeVersion,
+		Width:   (len(first) - 1) / (cw + 1),
+		Height:  len(lines) / 2,
+		Start:   Coordinate{-1, -1},
+	}
+	d.Treasure = d.Start
+	d.Walls = make([][]Survey, d.Height)
+
+	// at returns the character at column i of line j, which is a space beyond the end.
+	at := func(j, i int) rune {
+		if i < len(lines[j]) {
+			return lines[j][i]
+		}
+		return ' '
+	}
+	// hwall reports whether the horizontal line j has a wall above or below room x.
+	hwall := func(j, x int) bool {
+		for i := 1; i <= cw; i++ {
+			if at(j, x*(cw+1)+i) != ' ' {
+				return true
+			}
+		}
+		return false
+	}
+
+	for y := 0; y < d.Height; y++ {
+		d.Walls[y] = make([]Survey, d.Width)
+		j := 2*y + 1
+		for x := 0; x < d.Width; x++ {
+			d.Walls[y][x] = Survey{
+				Top:    hwall(j-1, x),
+				Bottom: hwall(j+1, x),
+				Left:   at(j, x*(cw+1)) != ' ',
+				Right:  at(j, (x+1)*(cw+1)) != ' ',
+			}
+
+			for i := 1; i <= cw; i++ {
+				switch at(j, x*(cw+1)+i) {
+				case 'S':
+					d.Start = Coordinate{x, y}
+				case 'T':
+					d.Treasure = Coordinate{x, y}
+				}
+			}
+		}
+	}
+
+	if err := d.Validate(); err != nil {
+		return MazeData{}, err
+	}
+	return d, nil
+}
+
+// Characters of mazes printed in StylePrint. The first character of a room
+// marks what it contains along with whether it has a bottom wall,
+// except for the path, whose bottom wall is the second character as in an empty room.
+const (
+	printTreasureBottom = '⏅'
+	printTreasure       = '⏃'
+	printStartBottom    = '⏂'
+	printStart          = '⏀'
+	printIcarusBottom   = '⏈'
+	printIcarus         = '⏆'
+	printPath           = '•'
+)
+
+// MarshalPrint returns d drawn in StylePrint
+// with Icarus at the start.
+func MarshalPrint(d MazeData) []byte {
+	return []byte(Renderer{}.draw(dataView(d, d.Start)))
+}
+
+// UnmarshalPrint parses a maze printed in StylePrint.
+// If the start is hidden by Icarus, Icarus's room is taken as the start.
+func UnmarshalPrint(b []byte) (MazeData, error) {
+	lines := textLines(b)
+	if len(lines) < 2 || len(lines[0]) < 4 || (len(lines[0])-1)%3 != 0 {
+		return MazeData{}, errors.New("mazelib: the first line of a printed maze must be like ____")
+	}
+
+	d := MazeData{
+		Version: MazeVersion,
+		Width:   (len(lines[0]) - 1) / 3,
+		Height:  len(lines) - 1,
+		Start:   Coordinate{-1, -1},
+	}
+	d.Treasure = d.Start
+	d.Walls = make([][]Survey, d.Height)
+	icarus := d.Start
+
+	for y := 0; y < d.Height; y++ {
+		line := lines[y+1]
+		if len(line) != len(lines[0]) {
+			return MazeData{}, fmt.Errorf("mazelib: line %d of a printed maze is %d characters long; want %d", y+2, len(line), len(lines[0]))
+		}
+
+		d.Walls[y] = make([]Survey, d.Width)
+		for x := 0; x < d.Width; x++ {
+			s := &d.Walls[y][x]
+			s.Top = y == 0 || d.Walls[y-1][x].Bottom
+			s.Left = x == 0 || d.Walls[y][x-1].Right
+			s.Right = line[3*x+3] == '|'
+
+			c := Coordinate{x, y}
+			switch line[3*x+1] {
+			case printTreasureBottom:
+				d.Treasure, s.Bottom = c, true
+			case printTreasure:
+				d.Treasure = c
+			case printStartBottom:
+				d.Start, s.Bottom = c, true
+			case printStart:
+				d.Start = c
+			case printIcarusBottom:
+				icarus, s.Bottom = c, true
+			case printIcarus:
+				icarus = c
+			case printPath:
+				s.Bottom = line[3*x+2] == '_'
+			case '_':
+				s.Bottom = true
+			}
+		}
+	}
+
+	if d.Start.X < 0 {
+		d.Start = icarus
+	}
+	if err := d.Validate(); err != nil {
+		return MazeData{}, err
+	}
+	return d, nil
+}
+
+// textLines splits b into lines of runes without line terminators or blank lines
+// at either end, so that mazes pasted with surrounding blank lines can be parsed.
+func textLines(b []byte) [][]rune {
+	s := strings.ReplaceAll(string(b), "\r\n", "\n")
+	s = strings.Trim(s, "\n")
+
+	var lines [][]rune
+	for _, l := range strings.Split(s, "\n") {
+		lines = append(lines, []rune(l))
+	}
+	return lines
+}
@@ -0,0 +1,93 @@
+package mazelib
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestASCIIRoundTrip(t *testing.T) {
+	want := uShaped()
+	want.Seed = 0 // not kept in text
+
+	formats := []struct {
+		name      string
+		marshal   func(MazeData) []byte
+		unmarshal func([]byte) (MazeData, error)
+	}{
+		{"ascii", MarshalASCII, UnmarshalASCII},
+		{"print", MarshalPrint, UnmarshalPrint},
+	}
+
+	for _, f := range formats {
+		b := f.marshal(want)
+		got, err := f.unmarshal(b)
+		if err != nil {
+			t.Errorf("%s: %v\n%s", f.name, err, b)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v; want %+v", f.name, got, want)
+		}
+	}
+}
+
+func TestUnmarshalASCII(t *testing.T) {
+	want := uShaped()
+	want.Seed = 0
+
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{"+---+---+\n| S     |\n+---+   +\n| T     |\n+---+---+\n", false},
+		// narrow rooms, surrounding blank lines, CRLF and trimmed trailing spaces
+		{"\r\n+--+--+\r\n|S    |\r\n+==+  +\r\n|T    |\r\n+--+--+\r\n\r\n", false},
+		// the outer wall is open
+		{"+---+---+\n  S     |\n+---+   +\n| T     |\n+---+---+\n", true},
+		// no treasure
+		{"+---+---+\n| S     |\n+---+   +\n|       |\n+---+---+\n", true},
+		{"+---+---+\n| S     |\n", true},
+	}
+
+	for _, tt := range tests {
+		got, err := UnmarshalASCII([]byte(tt.in))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: got error %v; want error %t", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %+v; want %+v", tt.in, got, want)
+		}
+	}
+}
+
+func TestUnmarshalPrint(t *testing.T) {
+	want := uShaped()
+	want.Seed = 0
+
+	// Icarus on the start, as Daedalus prints a maze before he moves
+	in := "_______\n|⏈ _  |\n|⏅____|\n"
+	got, err := UnmarshalPrint([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+}
+
+func TestUnmarshalPrintPath(t *testing.T) {
+	want := uShaped()
+	want.Seed = 0
+
+	// the path goes through (1, 1), which has a bottom wall
+	r := Renderer{Path: []Coordinate{{0, 0}, {1, 0}, {1, 1}}}
+	b := r.draw(dataView(want, want.Start))
+	got, err := UnmarshalPrint([]byte(b))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, b)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v; want %+v\n%s", got, want, b)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrVictory is an error representing the victory of Icarus.
+var ErrVictory = errors.New("Victory")
+
+// Errors reported by the server.
+// Clients can test a Reply against them with errors.Is(reply.Err(), ErrWall) etc.
+var (
+	ErrWall         = errors.New("Can't walk through walls")
+	ErrOutOfBounds  = errors.New("room outside of maze boundaries")
+	ErrBadDirection = errors.New("invalid direction")
+	ErrNoSession    = errors.New("no such session")
+	ErrFinished     = errors.New("the maze has already been solved")
+	ErrUnauthorized = errors.New("missing or invalid bearer token")
+)
+
+// ErrorCode identifies the kind of an error in a Reply.
+type ErrorCode string
+
+// Error codes in a Reply.
+const (
+	CodeWall         ErrorCode = "wall"
+	CodeOutOfBounds  ErrorCode = "out_of_bounds"
+	CodeBadDirection ErrorCode = "bad_direction"
+	CodeNoSession    ErrorCode = "no_session"
+	CodeFinished     ErrorCode = "finished"
+	CodeUnauthorized ErrorCode = "unauthorized"
+)
+
+var codeErrs = map[ErrorCode]error{
+	CodeWall:         ErrWall,
+	CodeOutOfBounds:  ErrOutOfBounds,
+	CodeBadDirection: ErrBadDirection,
+	CodeNoSession:    ErrNoSession,
+	CodeFinished:     ErrFinished,
+	CodeUnauthorized: ErrUnauthorized,
+}
+
+// CodeOf returns the ErrorCode of err.
+// If err is not one of the errors reported by the server, it returns "".
+func CodeOf(err error) ErrorCode {
+	for code, e := range codeErrs {
+		if errors.Is(err, e) {
+			return code
+		}
+	}
+	return ""
+}
+
+// Err returns the sentinel error identified by c, or nil if c is unknown.
+func (c ErrorCode) Err() error {
+	return codeErrs[c]
+}
+
+// HTTPStatus returns the HTTP status code of a reply with c.
+// Unknown codes are regarded as internal server errors.
+func (c ErrorCode) HTTPStatus() int {
+	switch c {
+	case CodeWall:
+		return http.StatusConflict
+	case CodeOutOfBounds:
+		return http.StatusUnprocessableEntity
+	case CodeBadDirection:
+		return http.StatusBadRequest
+	case CodeNoSession:
+		return http.StatusNotFound
+	case CodeFinished:
+		return http.StatusGone
+	case CodeUnauthorized:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ErrorReply returns a Reply describing err.
+func ErrorReply(err error) Reply {
+	return Reply{
+		Error:   true,
+		Message: err.Error(),
+		Code:    CodeOf(err),
+	}
+}
+
+// Err returns the error described by r, or nil if r is not an error.
+// The error matches the sentinel error of r.Code with errors.Is.
+func (r Reply) Err() error {
+	if !r.Error {
+		return nil
+	}
+	if err := r.Code.Err(); err != nil {
+		return &replyError{msg: r.Message, err: err}
+	}
+	return errors.New(r.Message)
+}
+
+// replyError is an error received in a Reply, which wraps the corresponding sentinel error.
+type replyError struct {
+	msg string
+	err error
+}
+
+func (e *replyError) Error() string { return e.msg }
+
+func (e *replyError) Unwrap() error { return e.err }
@@ -0,0 +1,49 @@
+package mazelib
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestReplyErr(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+	}{
+		{ErrWall, http.StatusConflict},
+		{ErrOutOfBounds, http.StatusUnprocessableEntity},
+		{ErrBadDirection, http.StatusBadRequest},
+		{ErrNoSession, http.StatusNotFound},
+		{ErrFinished, http.StatusGone},
+		{ErrUnauthorized, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		b, err := json.Marshal(ErrorReply(tt.err))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r Reply
+		if err := json.Unmarshal(b, &r); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := r.Err(); !errors.Is(got, tt.err) {
+			t.Errorf("%s: got %v; want it to be %v", b, got, tt.err)
+		}
+		if got := r.Code.HTTPStatus(); got != tt.wantStatus {
+			t.Errorf("%s: got status %d; want %d", b, got, tt.wantStatus)
+		}
+	}
+
+	if err := (Reply{}).Err(); err != nil {
+		t.Errorf("a successful reply should not be an error, but got %v", err)
+	}
+
+	err := Reply{Error: true, Message: "oops"}.Err()
+	if err == nil || err.Error() != "oops" || CodeOf(err) != "" {
+		t.Errorf("an error reply without code: got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+)
+
+// Default settings of RenderGIF.
+const (
+	DefaultGIFCellSize = 12
+	DefaultGIFDelay    = 5
+)
+
+// Indices of the colors in gifPalette.
+const (
+	gifFog = iota
+	gifWall
+	gifVisited
+	gifBacktracked
+	gifIcarus
+	gifStart
+	gifTreasure
+)
+
+var gifPalette = color.Palette{
+	gifFog:         color.RGBA{0x9e, 0x9e, 0x9e, 0xff},
+	gifWall:        color.RGBA{0x21, 0x21, 0x21, 0xff},
+	gifVisited:     color.RGBA{0xe3, 0xf2, 0xfd, 0xff},
+	gifBacktracked: color.RGBA{0xff, 0xcc, 0x80, 0xff},
+	gifIcarus:      color.RGBA{0xc6, 0x28, 0x28, 0xff},
+	gifStart:       color.RGBA{0x2e, 0x7d, 0x32, 0xff},
+	gifTreasure:    color.RGBA{0xf9, 0xa8, 0x25, 0xff},
+}
+
+// GIFOptions configures RenderGIF.
+type GIFOptions struct {
+	// CellSize is the size of a room in pixels. DefaultGIFCellSize is used if it is <= 0.
+	CellSize int
+	// Delay is the delay between frames in 100ths of a second.
+	// DefaultGIFDelay is used if it is <= 0.
+	Delay int
+}
+
+// RenderGIF animates r as a GIF image to w, one frame per step.
+// The rooms Icarus has not visited yet are hidden in the fog,
+// and the rooms he backtracked from are drawn in orange.
+func RenderGIF(w io.Writer, r Replay, opts GIFOptions) error {
+	d := r.Maze
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	c := opts.CellSize
+	if c <= 0 {
+		c = DefaultGIFCellSize
+	}
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = DefaultGIFDelay
+	}
+
+	canvas := image.NewPaletted(image.Rect(0, 0, d.Width*c, d.Height*c), gifPalette)
+	state := make([][]uint8, d.Height)
+	for y := range state {
+		state[y] = make([]uint8, d.Width)
+	}
+	icarus := d.Start
+
+	// paint draws the room at p on the canvas and returns its bounds.
+	paint := func(p Coordinate) image.Rectangle {
+		rect := image.Rect(p.X*c, p.Y*c, (p.X+1)*c, (p.Y+1)*c)
+		st := state[p.Y][p.X]
+		fill(canvas, rect, st)
+		if st == gifFog {
+			return rect
+		}
+
+		switch inset := c / 4; p {
+		case icarus:
+			fill(canvas, rect.Inset(inset), gifIcarus)
+		case d.Treasure:
+			fill(canvas, rect.Inset(inset), gifTreasure)
+		case d.Start:
+			fill(canvas, rect.Inset(inset), gifStart)
+		}
+
+		t := max(c/8, 1)
+		s := d.Walls[p.Y][p.X]
+		if s.Top {
+			fill(canvas, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+t), gifWall)
+		}
+		if s.Bottom {
+			fill(canvas, image.Rect(rect.Min.X, rect.Max.Y-t, rect.Max.X, rect.Max.Y), gifWall)
+		}
+		if s.Left {
+			fill(canvas, image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+t, rect.Max.Y), gifWall)
+		}
+		if s.Right {
+			fill(canvas, image.Rect(rect.Max.X-t, rect.Min.Y, rect.Max.X, rect.Max.Y), gifWall)
+		}
+		return rect
+	}
+
+	anim := &gif.GIF{}
+	// frame adds the part of the canvas within rect as a frame.
+	frame := func(rect image.Rectangle) {
+		img := image.NewPaletted(rect, gifPalette)
+		draw.Draw(img, rect, canvas, rect.Min, draw.Src)
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, delay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+	}
+
+	state[icarus.Y][icarus.X] = gifVisited
+	for y := 0; y < d.Height; y++ {
+		for x := 0; x < d.Width; x++ {
+			paint(Coordinate{x, y})
+		}
+	}
+	frame(canvas.Bounds())
+
+	in := func(p Coordinate) bool {
+		return p.X >= 0 && p.Y >= 0 && p.X < d.Width && p.Y < d.Height
+	}
+	for _, step := range r.Steps {
+		if step.Blocked || step.To == icarus || !in(step.To) {
+			// nothing changes, but the frame keeps the pace of the run
+			frame(image.Rect(0, 0, 1, 1))
+			continue
+		}
+
+		from := icarus
+		if state[step.To.Y][step.To.X] != gifFog {
+			// going back to a room he has been to
+			state[from.Y][from.X] = gifBacktracked
+		} else {
+			state[step.To.Y][step.To.X] = gifVisited
+		}
+		icarus = step.To
+		frame(paint(from).Union(paint(icarus)))
+	}
+
+	// hold the last frame
+	anim.Delay[len(anim.Delay)-1] = 100
+	return gif.EncodeAll(w, anim)
+}
+
+// fill fills rect of img with the color at idx of its palette.
+func fill(img *image.Paletted, rect image.Rectangle, idx uint8) {
+	rect = rect.Intersect(img.Rect)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.SetColorIndex(x, y, idx)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package mazelib
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+)
+
+func TestRenderGIF(t *testing.T) {
+	r := Replay{
+		Maze: uShaped(),
+		Steps: []Step{
+			{Direction: S, To: Coordinate{0, 0}, Blocked: true},
+			{Direction: E, To: Coordinate{1, 0}},
+			{Direction: W, To: Coordinate{0, 0}},
+			{Direction: E, To: Coordinate{1, 0}},
+			{Direction: S, To: Coordinate{1, 1}},
+			{Direction: W, To: Coordinate{0, 1}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderGIF(&buf, r, GIFOptions{CellSize: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(g.Image), len(r.Steps)+1; got != want {
+		t.Errorf("got %d frames; want %d", got, want)
+	}
+	if b := g.Image[0].Bounds(); b.Dx() != 20 || b.Dy() != 20 {
+		t.Errorf("got the first frame of %v; want 20x20", b)
+	}
+	// the frame of a step covers only the rooms left and entered
+	if b := g.Image[2].Bounds(); b.Dx() != 20 || b.Dy() != 10 {
+		t.Errorf("got the frame of a step of %v; want 20x10", b)
+	}
+
+	// backtracking from (1, 0) paints it orange
+	if got := g.Image[3].ColorIndexAt(15, 5); got != gifBacktracked {
+		t.Errorf("got color %d of a room backtracked from; want %d", got, gifBacktracked)
+	}
+}
@@ -19,11 +19,8 @@
 package mazelib
 
 import (
-	"errors"
-	"fmt"
 	"math/rand"
-	"os"
-	"strings"
+	"sort"
 )
 
 // Coordinate describes a location in the maze
@@ -34,10 +31,50 @@ type Coordinate struct {
 
 // Reply from the server to a request
 type Reply struct {
-	Survey  Survey `json:"survey"`
-	Victory bool   `json:"victory"`
-	Message string `json:"message"`
-	Error   bool   `json:"error"`
+	Survey  Survey    `json:"survey"`
+	Victory bool      `json:"victory"`
+	Message string    `json:"message"`
+	Error   bool      `json:"error"`
+	Code    ErrorCode `json:"code,omitempty"`
+	Session string    `json:"session,omitempty"`
+	// Seed is the seed the maze was generated from, returned on awake.
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// StatusOK is the status of a healthy server.
+const StatusOK = "ok"
+
+// Health is a reply from the server to a health check.
+type Health struct {
+	Status string `json:"status"`
+}
+
+// BatchMove is a request to move Icarus along Directions in order.
+type BatchMove struct {
+	Directions []string `json:"directions"`
+}
+
+// BatchReply from the server to a BatchMove.
+// Surveys holds the survey after each step taken,
+// and the embedded Reply describes the final status.
+type BatchReply struct {
+	Reply
+	Surveys []Survey `json:"surveys"`
+}
+
+// Actions of a Command.
+const (
+	ActionAwake = "awake"
+	ActionMove  = "move"
+	ActionDone  = "done"
+)
+
+// Command from a client to the server over a streaming connection.
+// Direction is used only by ActionMove, and Solver only by ActionAwake.
+type Command struct {
+	Action    string `json:"action"`
+	Direction string `json:"direction,omitempty"`
+	Solver    string `json:"solver,omitempty"`
 }
 
 // Survey Given a location, survey surrounding locations
@@ -89,6 +126,21 @@ func (d Direction) String() string {
 	}
 }
 
+// Step returns the coordinate of the room next to c in direction d.
+func (c Coordinate) Step(d Direction) Coordinate {
+	switch d {
+	case N:
+		c.Y--
+	case S:
+		c.Y++
+	case E:
+		c.X++
+	case W:
+		c.X--
+	}
+	return c
+}
+
 // Opposite returns the oppsite direction of d.
 func (d Direction) Opposite() Direction {
 	switch d {
@@ -105,9 +157,6 @@ func (d Direction) Opposite() Direction {
 	}
 }
 
-// ErrVictory is an error representing the victory of Icarus.
-var ErrVictory = errors.New("Victory")
-
 // Room contains the minimum informaion about a room in the maze.
 type Room struct {
 	Treasure bool
@@ -183,12 +232,16 @@ func (r *Room) IsLinked(room *Room) bool {
 	return found
 }
 
-// Neighbors returns all the neighbors around `r`.
+// Neighbors returns all the neighbors around `r` in the order of
+// north, east, south and west, so that mazes generated from them are reproducible.
 func (r *Room) Neighbors() []*Room {
 	nbrs := make([]*Room, 0, len(r.Nbr))
 	for nbr := range r.Nbr {
 		nbrs = append(nbrs, nbr)
 	}
+	sort.Slice(nbrs, func(i, j int) bool {
+		return r.Nbr[nbrs[i]] < r.Nbr[nbrs[j]]
+	})
 	return nbrs
 }
 
@@ -208,8 +261,8 @@ type MazeI interface {
 	MoveDown() error
 }
 
-// AvgScores calculates the avarage of `in`.
-func AvgScores(in []int) int {
+// AvgScores calculates the average of `in`.
+func AvgScores(in []int) float64 {
 	if len(in) == 0 {
 		return 0
 	}
@@ -219,67 +272,65 @@ func AvgScores(in []int) int {
 	for _, x := range in {
 		total += x
 	}
-	return total / (len(in))
+	return float64(total) / float64(len(in))
 }
 
-// PrintMaze : Function to Print Maze to Console
-func PrintMaze(m MazeI) {
-	ix, iy := m.Icarus()
+// Distances returns the number of steps from the room at (x, y) to every room of m
+// through open walls, indexed by [y][x]. Unreachable rooms are at a distance of -1.
+func Distances(m MazeI, x, y int) [][]int {
+	return distances(m.Width(), m.Height(), func(x, y int) Survey {
+		room, err := m.GetRoom(x, y)
+		if err != nil {
+			return Survey{Top: true, Right: true, Bottom: true, Left: true}
+		}
+		return room.Walls
+	}, x, y)
+}
 
-	fmt.Println("_" + strings.Repeat("___", m.Width()))
-	for y := 0; y < m.Height(); y++ {
-		str := ""
-		for x := 0; x < m.Width(); x++ {
-			if x == 0 {
-				str += "|"
-			}
-			r, err := m.GetRoom(x, y)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(-1)
-			}
-			s, err := m.Discover(x, y)
-			if err != nil {
-				fmt.Println(err)
-				os.Exit(-1)
-			}
-			if s.Bottom {
-				if r.Treasure {
-					str += "⏅_"
-				} else if r.Start {
-					str += "⏂_"
-				} else if ix == x && iy == y {
-					str += "⏈ "
-				} else {
-					str += "__"
-				}
-			} else {
-				if r.Treasure {
-					str += "⏃ "
-				} else if r.Start {
-					str += "⏀ "
-				} else if ix == x && iy == y {
-					str += "⏆ "
-				} else {
-					str += "  "
-				}
-			}
+// distances is Distances over a maze of w x h rooms whose walls are given by walls.
+func distances(w, h int, walls func(x, y int) Survey, x, y int) [][]int {
+	dist := make([][]int, h)
+	for j := range dist {
+		dist[j] = make([]int, w)
+		for i := range dist[j] {
+			dist[j][i] = -1
+		}
+	}
+	if x < 0 || y < 0 || x >= w || y >= h {
+		return dist
+	}
 
-			if s.Right {
-				str += "|"
-			} else {
-				str += "_"
+	dist[y][x] = 0
+	queue := []Coordinate{{x, y}}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+
+		s := walls(c.X, c.Y)
+		for _, step := range []struct {
+			wall bool
+			next Coordinate
+		}{
+			{s.Top, Coordinate{c.X, c.Y - 1}},
+			{s.Right, Coordinate{c.X + 1, c.Y}},
+			{s.Bottom, Coordinate{c.X, c.Y + 1}},
+			{s.Left, Coordinate{c.X - 1, c.Y}},
+		} {
+			n := step.next
+			if step.wall || n.X < 0 || n.Y < 0 || n.X >= w || n.Y >= h || dist[n.Y][n.X] >= 0 {
+				continue
 			}
-
+			dist[n.Y][n.X] = dist[c.Y][c.X] + 1
+			queue = append(queue, n)
 		}
-		fmt.Println(str)
 	}
+	return dist
 }
 
-// Shuffle shuffles rooms.
-func Shuffle(rooms []*Room) []*Room {
+// Shuffle shuffles rooms by using rnd.
+func Shuffle(rnd *rand.Rand, rooms []*Room) []*Room {
 	l := len(rooms)
-	idx := rand.Perm(l)
+	idx := rnd.Perm(l)
 	shfl := make([]*Room, l)
 	for i, j := range idx {
 		shfl[i] = rooms[j]
@@ -287,13 +338,13 @@ func Shuffle(rooms []*Room) []*Room {
 	return shfl
 }
 
-// Random returns an element of rooms randomly.
+// Random returns an element of rooms chosen by rnd.
 // If the length of rooms is zero, it returns nil.
-func Random(rooms []*Room) *Room {
+func Random(rnd *rand.Rand, rooms []*Room) *Room {
 	if len(rooms) == 0 {
 		return nil
 	}
 
-	idx := rand.Intn(len(rooms))
+	idx := rnd.Intn(len(rooms))
 	return rooms[idx]
 }
@@ -48,8 +48,8 @@ func TestLinks(t *testing.T) {
 }
 
 func TestShuffle(t *testing.T) {
-	// seed by which rand.Perm() returns indices of reverse order
-	rand.Seed(20)
+	// seed by which rnd.Perm() returns indices of reverse order
+	rnd := rand.New(rand.NewSource(2))
 
 	tests := []struct {
 		rooms []*Room
@@ -61,7 +61,7 @@ func TestShuffle(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		shfl := Shuffle(tt.rooms)
+		shfl := Shuffle(rnd, tt.rooms)
 		if len(shfl) != len(tt.rooms) {
 			t.Errorf("length: got %d but want %d", len(shfl), len(tt.rooms))
 		}
@@ -72,7 +72,7 @@ func TestShuffle(t *testing.T) {
 }
 
 func TestRandom(t *testing.T) {
-	rand.Seed(3)
+	rnd := rand.New(rand.NewSource(3))
 
 	rooms := []*Room{&Room{}, &Room{}, &Room{}}
 	tests := []struct {
@@ -86,7 +86,7 @@ func TestRandom(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		got := Random(tt.rooms)
+		got := Random(rnd, tt.rooms)
 		// compare with respect to pointer address
 		if got != tt.want {
 			t.Errorf("%v: got %p but want %p", tt.rooms, got, tt.want)
@@ -0,0 +1,188 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MazeVersion is the version of the JSON format of mazes written by MarshalMaze.
+const MazeVersion = 1
+
+// MazeData is the serializable form of a maze. In JSON, version 1 reads:
+//
+//	{
+//	  "version": 1,
+//	  "width": 2,
+//	  "height": 1,
+//	  "start": {"x": 0, "y": 0},
+//	  "treasure": {"x": 1, "y": 0},
+//	  "algorithm": "recursive-backtracker",
+//	  "seed": 42,
+//	  "walls": [[{"top": true, "right": false, "bottom": true, "left": true},
+//	             {"top": true, "right": true, "bottom": true, "left": false}]]
+//	}
+//
+// walls holds the walls of each room row by row from the top, i.e. indexed by [y][x].
+// The walls of adjacent rooms must agree, and the outer walls must be closed.
+// algorithm and seed are informational and may be omitted.
+type MazeData struct {
+	Version   int        `json:"version"`
+	Width     int        `json:"width"`
+	Height    int        `json:"height"`
+	Start     Coordinate `json:"start"`
+	Treasure  Coordinate `json:"treasure"`
+	Algorithm string     `json:"algorithm,omitempty"`
+	Seed      int64      `json:"seed,omitempty"`
+	Walls     [][]Survey `json:"walls"`
+}
+
+// Snapshot returns the data of m in the current version.
+// The start and the treasure are taken from the flags of the rooms,
+// and the metadata is left empty for the caller to fill in.
+func Snapshot(m MazeI) MazeData {
+	w, h := m.Width(), m.Height()
+	d := MazeData{
+		Version: MazeVersion,
+		Width:   w,
+		Height:  h,
+		Walls:   make([][]Survey, h),
+	}
+
+	for y := 0; y < h; y++ {
+		d.Walls[y] = make([]Survey, w)
+		for x := 0; x < w; x++ {
+			room, err := m.GetRoom(x, y)
+			if err != nil {
+				continue
+			}
+			d.Walls[y][x] = room.Walls
+			if room.Start {
+				d.Start = Coordinate{x, y}
+			}
+			if room.Treasure {
+				d.Treasure = Coordinate{x, y}
+			}
+		}
+	}
+	return d
+}
+
+// Validate reports whether d describes a well-formed maze.
+func (d MazeData) Validate() error {
+	if d.Width <= 0 || d.Height <= 0 {
+		return fmt.Errorf("mazelib: invalid dimensions %dx%d", d.Width, d.Height)
+	}
+	if len(d.Walls) != d.Height {
+		return fmt.Errorf("mazelib: %d rows of walls for height %d", len(d.Walls), d.Height)
+	}
+
+	in := func(c Coordinate) bool {
+		return c.X >= 0 && c.Y >= 0 && c.X < d.Width && c.Y < d.Height
+	}
+	if !in(d.Start) {
+		return fmt.Errorf("mazelib: start %v is out of the maze", d.Start)
+	}
+	if !in(d.Treasure) {
+		return fmt.Errorf("mazelib: treasure %v is out of the maze", d.Treasure)
+	}
+	if d.Start == d.Treasure {
+		return errors.New("mazelib: start and treasure are in the same room")
+	}
+
+	for y, row := range d.Walls {
+		if len(row) != d.Width {
+			return fmt.Errorf("mazelib: %d rooms in row %d for width %d", len(row), y, d.Width)
+		}
+	}
+
+	for y, row := range d.Walls {
+		for x, s := range row {
+			switch {
+			case y == 0 && !s.Top, y == d.Height-1 && !s.Bottom,
+				x == 0 && !s.Left, x == d.Width-1 && !s.Right:
+				return fmt.Errorf("mazelib: outer wall of room (%d, %d) is open", x, y)
+			case x+1 < d.Width && s.Right != row[x+1].Left:
+				return fmt.Errorf("mazelib: walls between rooms (%d, %d) and (%d, %d) disagree", x, y, x+1, y)
+			case y+1 < d.Height && s.Bottom != d.Walls[y+1][x].Top:
+				return fmt.Errorf("mazelib: walls between rooms (%d, %d) and (%d, %d) disagree", x, y, x, y+1)
+			}
+		}
+	}
+	return nil
+}
+
+// ShortestPath returns the rooms along a shortest path from the start to the treasure
+// of d, both inclusive, or nil if the treasure cannot be reached.
+func (d MazeData) ShortestPath() []Coordinate {
+	if d.Validate() != nil {
+		return nil
+	}
+
+	dist := distances(d.Width, d.Height, func(x, y int) Survey { return d.Walls[y][x] }, d.Treasure.X, d.Treasure.Y)
+	c := d.Start
+	if dist[c.Y][c.X] < 0 {
+		return nil
+	}
+
+	// walk downhill from the start to the treasure
+	path := []Coordinate{c}
+	for dist[c.Y][c.X] > 0 {
+		s := d.Walls[c.Y][c.X]
+		for _, step := range []struct {
+			wall bool
+			next Coordinate
+		}{
+			{s.Top, Coordinate{c.X, c.Y - 1}},
+			{s.Right, Coordinate{c.X + 1, c.Y}},
+			{s.Bottom, Coordinate{c.X, c.Y + 1}},
+			{s.Left, Coordinate{c.X - 1, c.Y}},
+		} {
+			if !step.wall && dist[step.next.Y][step.next.X] == dist[c.Y][c.X]-1 {
+				c = step.next
+				break
+			}
+		}
+		path = append(path, c)
+	}
+	return path
+}
+
+// MarshalMaze returns the JSON encoding of d in the current version.
+func MarshalMaze(d MazeData) ([]byte, error) {
+	d.Version = MazeVersion
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// UnmarshalMaze parses a maze encoded in JSON by MarshalMaze and validates it.
+func UnmarshalMaze(b []byte) (MazeData, error) {
+	var d MazeData
+	if err := json.Unmarshal(b, &d); err != nil {
+		return MazeData{}, err
+	}
+	if d.Version != MazeVersion {
+		return MazeData{}, fmt.Errorf("mazelib: unsupported maze version %d", d.Version)
+	}
+	if err := d.Validate(); err != nil {
+		return MazeData{}, err
+	}
+	return d, nil
+}
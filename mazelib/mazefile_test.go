@@ -0,0 +1,71 @@
+package mazelib
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// uShaped returns the data of a 2x2 maze whose only path goes around the wall in the middle.
+func uShaped() MazeData {
+	return MazeData{
+		Version:  MazeVersion,
+		Width:    2,
+		Height:   2,
+		Start:    Coordinate{0, 0},
+		Treasure: Coordinate{0, 1},
+		Seed:     7,
+		Walls: [][]Survey{
+			{{Top: true, Bottom: true, Left: true}, {Top: true, Right: true}},
+			{{Top: true, Bottom: true, Left: true}, {Right: true, Bottom: true}},
+		},
+	}
+}
+
+func TestMarshalMaze(t *testing.T) {
+	want := uShaped()
+	want.Version = 0 // set by MarshalMaze
+
+	b, err := MarshalMaze(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalMaze(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want.Version = MazeVersion
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+}
+
+func TestUnmarshalMazeInvalid(t *testing.T) {
+	tests := []struct {
+		modify func(d *MazeData)
+		want   string
+	}{
+		{func(d *MazeData) { d.Version = 2 }, "unsupported maze version"},
+		{func(d *MazeData) { d.Width = 0 }, "invalid dimensions"},
+		{func(d *MazeData) { d.Walls = d.Walls[:1] }, "rows of walls"},
+		{func(d *MazeData) { d.Walls[1] = d.Walls[1][:1] }, "rooms in row"},
+		{func(d *MazeData) { d.Treasure = Coordinate{2, 0} }, "out of the maze"},
+		{func(d *MazeData) { d.Treasure = d.Start }, "same room"},
+		{func(d *MazeData) { d.Walls[0][0].Left = false }, "outer wall"},
+		{func(d *MazeData) { d.Walls[0][0].Right = true }, "disagree"},
+	}
+
+	for _, tt := range tests {
+		d := uShaped()
+		tt.modify(&d)
+		b, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := UnmarshalMaze(b); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("got error %v; want one containing %q", err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Size of the pages of RenderPDF in points: A4 with margins of 2/3 inch.
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 48
+	pdfTitleSize  = 16
+)
+
+// PDFPage is a page of a PDF document.
+type PDFPage struct {
+	// Title is printed above the maze.
+	Title string
+	Maze  MazeData
+	// Solution draws a shortest path from the start to the treasure.
+	Solution bool
+}
+
+// RenderPDF writes pages as a PDF document of A4 pages to w,
+// each maze scaled to fit its page.
+func RenderPDF(w io.Writer, pages []PDFPage) error {
+	var contents [][]byte
+	for i, p := range pages {
+		if err := p.Maze.Validate(); err != nil {
+			return fmt.Errorf("page %d: %w", i+1, err)
+		}
+		contents = append(contents, pdfContent(p))
+	}
+
+	// objects 1 to 3 are the catalog, the page tree and the font,
+	// followed by a page and its content for each page
+	var (
+		b       bytes.Buffer
+		offsets []int
+	)
+	object := func(format string, args ...any) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\nendobj\n")
+	}
+
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	for i, c := range contents {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i)
+		object("<< /Length %d >>\nstream\n%s\nendstream", len(c), c)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// pdfContent returns the content stream drawing p.
+func pdfContent(p PDFPage) []byte {
+	d := p.Maze
+	var b bytes.Buffer
+
+	top := float64(pdfPageHeight - pdfMargin)
+	if p.Title != "" {
+		fmt.Fprintf(&b, "BT /F1 %d Tf %d %.2f Td (%s) Tj ET\n", pdfTitleSize, pdfMargin, top-pdfTitleSize, pdfEscape(p.Title))
+		top -= 2 * pdfTitleSize
+	}
+
+	// the largest rooms which fit the page, centered horizontally
+	boxW, boxH := float64(pdfPageWidth-2*pdfMargin), top-pdfMargin
+	c := min(boxW/float64(d.Width), boxH/float64(d.Height))
+	left := (pdfPageWidth - c*float64(d.Width)) / 2
+	// corner returns the point of the top left corner of the room at (x, y);
+	// PDF coordinates go up from the bottom of the page.
+	corner := func(x, y int) (float64, float64) {
+		return left + float64(x)*c, top - float64(y)*c
+	}
+
+	for _, room := range []struct {
+		p   Coordinate
+		rgb string
+	}{
+		{d.Start, "0.18 0.49 0.20"},
+		{d.Treasure, "0.98 0.66 0.15"},
+	} {
+		x, y := corner(room.p.X, room.p.Y)
+		fmt.Fprintf(&b, "%s rg %.2f %.2f %.2f %.2f re f\n", room.rgb, x+c/4, y-c*3/4, c/2, c/2)
+	}
+
+	if path := d.ShortestPath(); p.Solution && len(path) > 0 {
+		fmt.Fprintf(&b, "0.78 0.16 0.16 RG %.2f w 1 J 1 j\n", max(c/6, 0.5))
+		for i, r := range path {
+			x, y := corner(r.X, r.Y)
+			op := "l"
+			if i == 0 {
+				op = "m"
+			}
+			fmt.Fprintf(&b, "%.2f %.2f %s\n", x+c/2, y-c/2, op)
+		}
+		b.WriteString("S\n")
+	}
+
+	// each wall is drawn once as in RenderSVG
+	fmt.Fprintf(&b, "0 0 0 RG %.2f w 2 J\n", max(c/12, 0.5))
+	line := func(x1, y1, x2, y2 float64) {
+		fmt.Fprintf(&b, "%.2f %.2f m %.2f %.2f l\n", x1, y1, x2, y2)
+	}
+	for y, row := range d.Walls {
+		for x, s := range row {
+			x0, y0 := corner(x, y)
+			if s.Top {
+				line(x0, y0, x0+c, y0)
+			}
+			if s.Left {
+				line(x0, y0, x0, y0-c)
+			}
+			if s.Right && x == d.Width-1 {
+				line(x0+c, y0, x0+c, y0-c)
+			}
+			if s.Bottom && y == d.Height-1 {
+				line(x0, y0-c, x0+c, y0-c)
+			}
+		}
+	}
+	b.WriteString("S")
+
+	return b.Bytes()
+}
+
+// pdfEscape escapes s for a PDF string literal.
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
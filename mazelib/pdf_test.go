@@ -0,0 +1,61 @@
+package mazelib
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRenderPDF(t *testing.T) {
+	pages := []PDFPage{
+		{Title: "Maze (1)", Maze: uShaped()},
+		{Title: `Answer \1`, Maze: uShaped(), Solution: true},
+	}
+	var buf bytes.Buffer
+	if err := RenderPDF(&buf, pages); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("not a PDF document:\n%s", out)
+	}
+	if got := strings.Count(out, "/Type /Page "); got != len(pages) {
+		t.Errorf("got %d pages; want %d", got, len(pages))
+	}
+	for _, want := range []string{`(Maze \(1\)) Tj`, `(Answer \\1) Tj`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("title %s is missing", want)
+		}
+	}
+	// only the answer draws the path, in red
+	if got := strings.Count(out, "0.78 0.16 0.16 RG"); got != 1 {
+		t.Errorf("got %d solutions; want 1", got)
+	}
+
+	// every entry of the cross-reference table points at its object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	if m == nil {
+		t.Fatal("startxref is missing")
+	}
+	xref, _ := strconv.Atoi(m[1])
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out[xref:], -1)
+	if len(entries) != 3+2*len(pages) {
+		t.Fatalf("got %d objects; want %d", len(entries), 3+2*len(pages))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(e[1])
+		if want := fmt.Sprintf("%d 0 obj", i+1); !strings.HasPrefix(out[off:], want) {
+			t.Errorf("offset %d of object %d points at %.10q", off, i+1, out[off:])
+		}
+	}
+
+	d := uShaped()
+	d.Walls[0][0].Top = false
+	if err := RenderPDF(&bytes.Buffer{}, []PDFPage{{Maze: d}}); err == nil {
+		t.Error("RenderPDF should fail for an invalid maze")
+	}
+}
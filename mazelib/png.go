@@ -0,0 +1,213 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// Theme is the colors of a raster image of a maze.
+// The rooms of a heatmap are colored from HeatNear at the start to HeatFar
+// at the room farthest from it.
+type Theme struct {
+	Background color.RGBA
+	Wall       color.RGBA
+	Start      color.RGBA
+	Treasure   color.RGBA
+	Path       color.RGBA
+	Optimal    color.RGBA
+	HeatNear   color.RGBA
+	HeatFar    color.RGBA
+}
+
+// Themes of raster images.
+var (
+	LightTheme = Theme{
+		Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Wall:       color.RGBA{0x21, 0x21, 0x21, 0xff},
+		Start:      color.RGBA{0x2e, 0x7d, 0x32, 0xff},
+		Treasure:   color.RGBA{0xf9, 0xa8, 0x25, 0xff},
+		Path:       color.RGBA{0x15, 0x65, 0xc0, 0xff},
+		Optimal:    color.RGBA{0xc6, 0x28, 0x28, 0xff},
+		HeatNear:   color.RGBA{0xe3, 0xf2, 0xfd, 0xff},
+		HeatFar:    color.RGBA{0xef, 0x9a, 0x9a, 0xff},
+	}
+	DarkTheme = Theme{
+		Background: color.RGBA{0x1e, 0x1e, 0x1e, 0xff},
+		Wall:       color.RGBA{0xe0, 0xe0, 0xe0, 0xff},
+		Start:      color.RGBA{0x66, 0xbb, 0x6a, 0xff},
+		Treasure:   color.RGBA{0xff, 0xd5, 0x4f, 0xff},
+		Path:       color.RGBA{0x64, 0xb5, 0xf6, 0xff},
+		Optimal:    color.RGBA{0xef, 0x53, 0x50, 0xff},
+		HeatNear:   color.RGBA{0x1a, 0x23, 0x7e, 0xff},
+		HeatFar:    color.RGBA{0xb7, 0x1c, 0x1c, 0xff},
+	}
+	// PrintTheme is black on white for printing puzzles.
+	PrintTheme = Theme{
+		Background: color.RGBA{0xff, 0xff, 0xff, 0xff},
+		Wall:       color.RGBA{0x00, 0x00, 0x00, 0xff},
+		Start:      color.RGBA{0x60, 0x60, 0x60, 0xff},
+		Treasure:   color.RGBA{0x00, 0x00, 0x00, 0xff},
+		Path:       color.RGBA{0x90, 0x90, 0x90, 0xff},
+		Optimal:    color.RGBA{0x40, 0x40, 0x40, 0xff},
+		HeatNear:   color.RGBA{0xff, 0xff, 0xff, 0xff},
+		HeatFar:    color.RGBA{0xa0, 0xa0, 0xa0, 0xff},
+	}
+)
+
+// Themes maps the names of the themes to them.
+var Themes = map[string]Theme{
+	"light": LightTheme,
+	"dark":  DarkTheme,
+	"print": PrintTheme,
+}
+
+// PNGOptions configures RenderPNG.
+type PNGOptions struct {
+	// CellSize is the size of a room in pixels. DefaultCellSize is used if it is <= 0.
+	CellSize int
+	// WallThickness is the thickness of walls in pixels.
+	// An eighth of CellSize is used if it is <= 0.
+	WallThickness int
+	// Theme is LightTheme if it is zero.
+	Theme Theme
+	// Heatmap colors the rooms by their distances from the start.
+	Heatmap bool
+	// Path is the rooms Icarus went through in order, drawn if not empty.
+	Path []Coordinate
+	// Optimal draws a shortest path from the start to the treasure.
+	Optimal bool
+}
+
+// RenderPNG draws d as a PNG image to w.
+func RenderPNG(w io.Writer, d MazeData, opts PNGOptions) error {
+	img, err := Raster(d, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Raster draws d as an image as RenderPNG does.
+func Raster(d MazeData, opts PNGOptions) (*image.RGBA, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := opts.CellSize
+	if c <= 0 {
+		c = DefaultCellSize
+	}
+	t := opts.WallThickness
+	if t <= 0 {
+		t = max(c/8, 1)
+	}
+	th := opts.Theme
+	if th == (Theme{}) {
+		th = LightTheme
+	}
+	margin := c / 2
+
+	img := image.NewRGBA(image.Rect(0, 0, d.Width*c+2*margin, d.Height*c+2*margin))
+	paint := func(r image.Rectangle, col color.RGBA) {
+		draw.Draw(img, r, &image.Uniform{col}, image.Point{}, draw.Src)
+	}
+	// room returns the bounds of the room at p.
+	room := func(p Coordinate) image.Rectangle {
+		return image.Rect(margin+p.X*c, margin+p.Y*c, margin+(p.X+1)*c, margin+(p.Y+1)*c)
+	}
+	// center returns the pixel at the center of the room at p.
+	center := func(p Coordinate) image.Point {
+		r := room(p)
+		return image.Pt((r.Min.X+r.Max.X)/2, (r.Min.Y+r.Max.Y)/2)
+	}
+
+	paint(img.Bounds(), th.Background)
+
+	if opts.Heatmap {
+		dist := distances(d.Width, d.Height, func(x, y int) Survey { return d.Walls[y][x] }, d.Start.X, d.Start.Y)
+		farthest := 0
+		for _, row := range dist {
+			for _, v := range row {
+				farthest = max(farthest, v)
+			}
+		}
+		for y, row := range dist {
+			for x, v := range row {
+				if v >= 0 && farthest > 0 {
+					paint(room(Coordinate{x, y}), blend(th.HeatNear, th.HeatFar, float64(v)/float64(farthest)))
+				}
+			}
+		}
+	}
+
+	paint(room(d.Start).Inset(c/4), th.Start)
+	paint(room(d.Treasure).Inset(c/4), th.Treasure)
+
+	// polyline draws path through the centers of its rooms with lines of width.
+	polyline := func(path []Coordinate, col color.RGBA, width int) {
+		for i := 1; i < len(path); i++ {
+			a, b := center(path[i-1]), center(path[i])
+			seg := image.Rectangle{a, b}.Canon()
+			seg.Min = seg.Min.Sub(image.Pt(width/2, width/2))
+			seg.Max = seg.Max.Add(image.Pt(width-width/2, width-width/2))
+			paint(seg, col)
+		}
+	}
+	polyline(opts.Path, th.Path, max(c/6, 1))
+	if opts.Optimal {
+		polyline(d.ShortestPath(), th.Optimal, max(c/10, 1))
+	}
+
+	// walls are centered on the borders between rooms
+	hwall := func(x0, x1, y int) {
+		paint(image.Rect(x0-t/2, y-t/2, x1+t-t/2, y+t-t/2), th.Wall)
+	}
+	vwall := func(x, y0, y1 int) {
+		paint(image.Rect(x-t/2, y0-t/2, x+t-t/2, y1+t-t/2), th.Wall)
+	}
+	for y, row := range d.Walls {
+		for x, s := range row {
+			r := room(Coordinate{x, y})
+			if s.Top {
+				hwall(r.Min.X, r.Max.X, r.Min.Y)
+			}
+			if s.Left {
+				vwall(r.Min.X, r.Min.Y, r.Max.Y)
+			}
+			if s.Right && x == d.Width-1 {
+				vwall(r.Max.X, r.Min.Y, r.Max.Y)
+			}
+			if s.Bottom && y == d.Height-1 {
+				hwall(r.Min.X, r.Max.X, r.Max.Y)
+			}
+		}
+	}
+
+	return img, nil
+}
+
+// blend returns the color at ratio f of the way from a to b.
+func blend(a, b color.RGBA, f float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + f*(float64(y)-float64(x)) + 0.5)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
@@ -0,0 +1,79 @@
+package mazelib
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestRenderPNG(t *testing.T) {
+	tests := []struct {
+		opts      PNGOptions
+		wantStart color.RGBA // a corner of the start room
+		wantFar   color.RGBA // a corner of the treasure room, 3 rooms away
+	}{
+		{PNGOptions{}, LightTheme.Background, LightTheme.Background},
+		{PNGOptions{Theme: DarkTheme, Optimal: true}, DarkTheme.Background, DarkTheme.Background},
+		{PNGOptions{Heatmap: true}, LightTheme.HeatNear, LightTheme.HeatFar},
+		{PNGOptions{Theme: PrintTheme, Heatmap: true, Path: []Coordinate{{0, 0}, {1, 0}}}, PrintTheme.HeatNear, PrintTheme.HeatFar},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := RenderPNG(&buf, uShaped(), tt.opts); err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%+v: invalid PNG: %v", tt.opts, err)
+		}
+
+		// 2x2 rooms of 24 pixels with a margin of 12 pixels
+		if b := img.Bounds(); b.Dx() != 72 || b.Dy() != 72 {
+			t.Fatalf("%+v: got size %v", tt.opts, b)
+		}
+		th := tt.opts.Theme
+		if th == (Theme{}) {
+			th = LightTheme
+		}
+		for _, px := range []struct {
+			x, y int
+			want color.RGBA
+		}{
+			{12, 12, th.Wall},  // outer corner
+			{24, 36, th.Wall},  // the wall between the start and the treasure
+			{19, 19, th.Start}, // off the paths through the centers
+			{19, 43, th.Treasure},
+			{16, 16, tt.wantStart},
+			{16, 40, tt.wantFar},
+		} {
+			if got := color.RGBAModel.Convert(img.At(px.x, px.y)); got != px.want {
+				t.Errorf("%+v: pixel (%d, %d) = %v; want %v", tt.opts, px.x, px.y, got, px.want)
+			}
+		}
+	}
+
+	d := uShaped()
+	d.Walls[0][0].Top = false
+	if err := RenderPNG(&bytes.Buffer{}, d, PNGOptions{}); err == nil {
+		t.Error("RenderPNG should fail for an invalid maze")
+	}
+}
+
+func TestBlend(t *testing.T) {
+	a, b := color.RGBA{0, 100, 200, 255}, color.RGBA{100, 0, 200, 255}
+	tests := []struct {
+		f    float64
+		want color.RGBA
+	}{
+		{0, a},
+		{1, b},
+		{0.5, color.RGBA{50, 50, 200, 255}},
+	}
+	for _, tt := range tests {
+		if got := blend(a, b, tt.f); got != tt.want {
+			t.Errorf("blend(%v) = %v; want %v", tt.f, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Style is the characters a Renderer draws mazes in.
+type Style int
+
+// Styles of Renderer.
+const (
+	// StylePrint draws mazes in the characters Daedalus has always printed them in.
+	StylePrint Style = iota
+	// StyleASCII draws mazes in the grid of MarshalASCII.
+	StyleASCII
+	// StyleBox draws mazes in box-drawing characters.
+	StyleBox
+)
+
+// Styles maps the names of the styles to them.
+var Styles = map[string]Style{
+	"print": StylePrint,
+	"ascii": StyleASCII,
+	"box":   StyleBox,
+}
+
+// ANSI escape sequences of the colors of a Renderer.
+const (
+	ansiReset    = "\x1b[0m"
+	ansiStart    = "\x1b[32m"
+	ansiTreasure = "\x1b[33m"
+	ansiIcarus   = "\x1b[1;31m"
+	ansiPath     = "\x1b[34m"
+	ansiFog      = "\x1b[90m"
+	ansiRevisit  = "\x1b[35m"
+)
+
+// Renderer draws mazes as text. The zero value draws them
+// in the characters of MarshalPrint with Icarus in his room.
+type Renderer struct {
+	Style Style
+	// Color colors the rooms with ANSI escape sequences.
+	Color bool
+	// Fog hides the rooms Icarus has not visited yet.
+	Fog bool
+	// Path is marked in the rooms it goes through.
+	Path []Coordinate
+	// Visits, if not nil, is how many times Icarus has entered each room,
+	// shown in the rooms without marks. It is not shown in StylePrint,
+	// whose rooms are too narrow for it.
+	Visits [][]int
+}
+
+// Render draws m to w.
+func (r Renderer) Render(w io.Writer, m MazeI) error {
+	v := view{
+		width:   m.Width(),
+		height:  m.Height(),
+		walls:   make([][]Survey, m.Height()),
+		visited: make([][]bool, m.Height()),
+	}
+	v.icarus.X, v.icarus.Y = m.Icarus()
+	for y := range v.walls {
+		v.walls[y] = make([]Survey, v.width)
+		v.visited[y] = make([]bool, v.width)
+		for x := range v.walls[y] {
+			room, err := m.GetRoom(x, y)
+			if err != nil {
+				return err
+			}
+			if v.walls[y][x], err = m.Discover(x, y); err != nil {
+				return err
+			}
+			v.visited[y][x] = room.Visited
+			if room.Start {
+				v.start = Coordinate{x, y}
+			}
+			if room.Treasure {
+				v.treasure = Coordinate{x, y}
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, r.draw(v))
+	return err
+}
+
+// RenderData draws d to w with Icarus in the room at icarus.
+// The rooms of d count as visited only if they are on the path of r.
+func (r Renderer) RenderData(w io.Writer, d MazeData, icarus Coordinate) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, r.draw(dataView(d, icarus)))
+	return err
+}
+
+// RenderSighting draws what a spectator sees in s to w: the rooms Icarus
+// has visited with how many times he has entered them.
+func (r Renderer) RenderSighting(w io.Writer, s Sighting) error {
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if len(s.Rooms) != s.Height {
+		return fmt.Errorf("mazelib: a sighting has %d rows; want %d", len(s.Rooms), s.Height)
+	}
+
+	v := view{
+		width:    s.Width,
+		height:   s.Height,
+		walls:    make([][]Survey, s.Height),
+		start:    Coordinate{-1, -1},
+		treasure: Coordinate{-1, -1},
+		icarus:   s.Icarus,
+		visited:  make([][]bool, s.Height),
+	}
+	for y, row := range s.Rooms {
+		if len(row) != s.Width {
+			return fmt.Errorf("mazelib: row %d of a sighting has %d rooms; want %d", y, len(row), s.Width)
+		}
+		v.walls[y] = make([]Survey, s.Width)
+		v.visited[y] = make([]bool, s.Width)
+		for x, room := range row {
+			if room == nil {
+				continue
+			}
+			v.walls[y][x], v.visited[y][x] = room.Walls, true
+			if room.Start {
+				v.start = Coordinate{x, y}
+			}
+			if room.Treasure {
+				v.treasure = Coordinate{x, y}
+			}
+		}
+	}
+
+	r.Fog = true
+	if r.Visits == nil {
+		r.Visits = s.Visits()
+	}
+	_, err := io.WriteString(w, r.draw(v))
+	return err
+}
+
+// view is what a Renderer draws.
+type view struct {
+	width, height           int
+	walls                   [][]Survey
+	start, treasure, icarus Coordinate
+	// visited is nil if Icarus has visited no room.
+	visited [][]bool
+}
+
+// dataView returns the view of d with Icarus at icarus.
+func dataView(d MazeData, icarus Coordinate) view {
+	return view{
+		width:    d.Width,
+		height:   d.Height,
+		walls:    d.Walls,
+		start:    d.Start,
+		treasure: d.Treasure,
+		icarus:   icarus,
+	}
+}
+
+// draw returns v drawn as text.
+func (r Renderer) draw(v view) string {
+	onPath := make(map[Coordinate]bool, len(r.Path))
+	for _, p := range r.Path {
+		onPath[p] = true
+	}
+	// visible tells whether the room at (x, y) is in the maze and out of the fog.
+	visible := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= v.width || y >= v.height {
+			return false
+		}
+		p := Coordinate{x, y}
+		return !r.Fog || p == v.icarus || onPath[p] || (v.visited != nil && v.visited[y][x])
+	}
+	paint := func(s, color string) string {
+		if !r.Color || strings.TrimSpace(s) == "" {
+			return s
+		}
+		return color + s + ansiReset
+	}
+	// room returns what the room at p contains, as the marks of the style
+	// for the treasure, the start, Icarus and the path.
+	room := func(p Coordinate, marks [4]string) string {
+		switch {
+		case p == v.treasure:
+			return paint(marks[0], ansiTreasure)
+		case p == v.start:
+			return paint(marks[1], ansiStart)
+		case p == v.icarus:
+			return paint(marks[2], ansiIcarus)
+		case onPath[p]:
+			return paint(marks[3], ansiPath)
+		}
+		return ""
+	}
+	// visits returns how many times Icarus has entered the room at p
+	// centered in 3 characters, or "" if it is unknown.
+	visits := func(p Coordinate) string {
+		if p.Y >= len(r.Visits) || p.X >= len(r.Visits[p.Y]) || r.Visits[p.Y][p.X] <= 0 {
+			return ""
+		}
+		n := r.Visits[p.Y][p.X]
+		s := strconv.Itoa(min(n, 999))
+		switch len(s) {
+		case 1:
+			s = " " + s + " "
+		case 2:
+			s = " " + s
+		}
+		if n > 1 {
+			// Icarus has wasted steps coming back here
+			return paint(s, ansiRevisit)
+		}
+		return s
+	}
+
+	if r.Style == StylePrint {
+		return r.drawPrint(v, visible, room, paint)
+	}
+
+	cs := asciiChars
+	if r.Style == StyleBox {
+		cs = boxChars
+	}
+	fog := paint(cs.fog, ansiFog)
+
+	// hwall returns the wall above the room at (x, y).
+	hwall := func(x, y int) (known, wall bool) {
+		switch {
+		case visible(x, y):
+			return true, v.walls[y][x].Top
+		case visible(x, y-1):
+			return true, v.walls[y-1][x].Bottom
+		}
+		return false, false
+	}
+	// vwall returns the wall on the left of the room at (x, y).
+	vwall := func(x, y int) (known, wall bool) {
+		switch {
+		case visible(x, y):
+			return true, v.walls[y][x].Left
+		case visible(x-1, y):
+			return true, v.walls[y][x-1].Right
+		}
+		return false, false
+	}
+
+	var b strings.Builder
+	hline := func(y int) {
+		for x := 0; x <= v.width; x++ {
+			if !visible(x-1, y-1) && !visible(x, y-1) && !visible(x-1, y) && !visible(x, y) {
+				b.WriteString(fog)
+			} else {
+				_, left := hwall(x-1, y)
+				_, right := hwall(x, y)
+				_, up := vwall(x, y-1)
+				_, down := vwall(x, y)
+				b.WriteString(cs.corner(left, right, up, down))
+			}
+			if x == v.width {
+				break
+			}
+			switch known, wall := hwall(x, y); {
+			case !known:
+				b.WriteString(strings.Repeat(fog, 3))
+			case wall:
+				b.WriteString(cs.hwall)
+			default:
+				b.WriteString("   ")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	for y := 0; y < v.height; y++ {
+		hline(y)
+		for x := 0; x <= v.width; x++ {
+			switch known, wall := vwall(x, y); {
+			case !known:
+				b.WriteString(fog)
+			case wall:
+				b.WriteString(cs.vwall)
+			default:
+				b.WriteString(" ")
+			}
+			if x == v.width {
+				break
+			}
+			if !visible(x, y) {
+				b.WriteString(strings.Repeat(fog, 3))
+			} else if s := room(Coordinate{x, y}, cs.marks); s != "" {
+				b.WriteString(" " + s + " ")
+			} else if s := visits(Coordinate{x, y}); s != "" {
+				b.WriteString(s)
+			} else {
+				b.WriteString("   ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	hline(v.height)
+
+	return b.String()
+}
+
+// drawPrint returns v drawn in the characters of MarshalPrint.
+func (r Renderer) drawPrint(v view, visible func(x, y int) bool,
+	room func(Coordinate, [4]string) string, paint func(s, color string) string) string {
+	fog := paint("░", ansiFog)
+
+	var b strings.Builder
+	b.WriteString("_" + strings.Repeat("___", v.width) + "\n")
+	for y := 0; y < v.height; y++ {
+		b.WriteString("|")
+		for x := 0; x < v.width; x++ {
+			s := v.walls[y][x]
+			p := Coordinate{x, y}
+			switch {
+			case !visible(x, y):
+				b.WriteString(fog + fog)
+			case s.Bottom:
+				if c := room(p, printMarksBottom); c != "" && p == v.icarus && p != v.treasure && p != v.start {
+					// Icarus has always stood on a bottom wall without it
+					b.WriteString(c + " ")
+				} else if c != "" {
+					b.WriteString(c + "_")
+				} else {
+					b.WriteString("__")
+				}
+			default:
+				if c := room(p, printMarks); c != "" {
+					b.WriteString(c + " ")
+				} else {
+					b.WriteString("  ")
+				}
+			}
+
+			// the right wall of a room in the fog is known from its neighbor
+			right, known := s.Right, visible(x, y)
+			if !known && visible(x+1, y) {
+				right, known = v.walls[y][x+1].Left, true
+			}
+			switch {
+			case !known:
+				b.WriteString(fog)
+			case right:
+				b.WriteString("|")
+			default:
+				b.WriteString("_")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Marks of the treasure, the start, Icarus and the path in the rooms
+// drawn in the characters of MarshalPrint, with and without a bottom wall.
+var (
+	printMarksBottom = [4]string{string(printTreasureBottom), string(printStartBottom), string(printIcarusBottom), string(printPath)}
+	printMarks       = [4]string{string(printTreasure), string(printStart), string(printIcarus), string(printPath)}
+)
+
+// gridChars is the characters of a grid drawing of a maze.
+type gridChars struct {
+	hwall, vwall, fog string
+	// marks of the treasure, the start, Icarus and the path
+	marks [4]string
+	// corner returns the corner where walls meet from the given sides.
+	corner func(left, right, up, down bool) string
+}
+
+var asciiChars = gridChars{
+	hwall: "---",
+	vwall: "|",
+	fog:   "#",
+	marks: [4]string{"T", "S", "@", "."},
+	corner: func(left, right, up, down bool) string {
+		return "+"
+	},
+}
+
+// boxCorners are the box-drawing characters indexed by the sides
+// walls meet from: left, right, up and down from the lowest bit.
+var boxCorners = []rune(" ╴╶─╵┘└┴╷┐┌┬│┤├┼")
+
+var boxChars = gridChars{
+	hwall: "───",
+	vwall: "│",
+	fog:   "░",
+	marks: [4]string{"T", "S", "@", "·"},
+	corner: func(left, right, up, down bool) string {
+		i := 0
+		for bit, side := range []bool{left, right, up, down} {
+			if side {
+				i |= 1 << bit
+			}
+		}
+		return string(boxCorners[i])
+	},
+}
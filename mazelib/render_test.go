@@ -0,0 +1,117 @@
+package mazelib
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderData(t *testing.T) {
+	tests := []struct {
+		r    Renderer
+		want string
+	}{
+		{Renderer{}, `
+_______
+|⏂__  |
+|⏅__⏈ |
+`},
+		{Renderer{Style: StyleASCII}, `
++---+---+
+| S     |
++---+   +
+| T   @ |
++---+---+
+`},
+		{Renderer{Style: StyleBox}, `
+┌───────┐
+│ S     │
+├───╴   │
+│ T   @ │
+└───────┘
+`},
+		{Renderer{Style: StyleASCII, Path: []Coordinate{{0, 0}, {1, 0}, {1, 1}}}, `
++---+---+
+| S   . |
++---+   +
+| T   @ |
++---+---+
+`},
+		{Renderer{Style: StyleASCII, Fog: true}, `
+#########
+#########
+####+   +
+####  @ |
+####+---+
+`},
+		{Renderer{Fog: true, Path: []Coordinate{{1, 0}, {1, 1}}}, `
+_______
+|░░_• |
+|░░_⏈ |
+`},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := tt.r.RenderData(&buf, uShaped(), Coordinate{1, 1}); err != nil {
+			t.Fatal(err)
+		}
+		if want := strings.TrimPrefix(tt.want, "\n"); buf.String() != want {
+			t.Errorf("%+v: got\n%s\nwant\n%s", tt.r, buf.String(), want)
+		}
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (Renderer{Color: true}).RenderData(&buf, uShaped(), Coordinate{1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{ansiStart + "⏂" + ansiReset + "_", ansiTreasure + "⏅" + ansiReset, ansiIcarus + "⏈" + ansiReset + " "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%q is missing in\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRenderSighting(t *testing.T) {
+	d := uShaped()
+	sg := Sighting{
+		Width:  2,
+		Height: 2,
+		Icarus: Coordinate{1, 1},
+		Steps:  14,
+		Rooms: [][]*Sight{
+			{{Walls: d.Walls[0][0], Visits: 3, Start: true}, {Walls: d.Walls[0][1], Visits: 12}},
+			{nil, {Walls: d.Walls[1][1], Visits: 1}},
+		},
+	}
+	if got := sg.Wasted(); got != 13 {
+		t.Errorf("got %d wasted steps; want 13", got)
+	}
+
+	var buf bytes.Buffer
+	if err := (Renderer{Style: StyleBox}).RenderSighting(&buf, sg); err != nil {
+		t.Fatal(err)
+	}
+	// walls next to the fog are drawn only as far as they are seen
+	want := `┌───────┐
+│ S   12│
+└───╴   │
+░░░░  @ │
+░░░░╶───┘
+`
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	sg.Rooms = sg.Rooms[:1]
+	if err := (Renderer{}).RenderSighting(&buf, sg); err == nil {
+		t.Error("RenderSighting should fail for missing rows")
+	}
+	sg.Reply = ErrorReply(ErrNoSession)
+	if err := (Renderer{}).RenderSighting(&buf, sg); !errors.Is(err, ErrNoSession) {
+		t.Errorf("got %v; want %v", err, ErrNoSession)
+	}
+}
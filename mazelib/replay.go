@@ -0,0 +1,30 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+// Step is an attempt of Icarus to move one step, as recorded in a move log.
+type Step struct {
+	Direction Direction `json:"direction"`
+	// To is where Icarus is after the attempt, which is unchanged if a wall blocked him.
+	To      Coordinate `json:"to"`
+	Blocked bool       `json:"blocked,omitempty"`
+}
+
+// Replay is the record of Icarus playing a maze from its start.
+type Replay struct {
+	Maze  MazeData `json:"maze"`
+	Steps []Step   `json:"steps"`
+}
@@ -0,0 +1,67 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+// Sighting from the server to a spectator: what Icarus has revealed of
+// his maze so far. The embedded Reply reports errors, the session, and
+// whether he has found the treasure.
+type Sighting struct {
+	Reply
+	Solver string     `json:"solver,omitempty"`
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	Icarus Coordinate `json:"icarus"`
+	Steps  int        `json:"steps"`
+	// Rooms holds the rooms by row, with nil for the rooms in the fog.
+	// The server may reveal the rooms Icarus has not visited with no visits.
+	Rooms [][]*Sight `json:"rooms"`
+}
+
+// Sight is a room of a Sighting.
+type Sight struct {
+	Walls Survey `json:"walls"`
+	// Visits is how many times Icarus has entered the room.
+	Visits   int  `json:"visits"`
+	Start    bool `json:"start,omitempty"`
+	Treasure bool `json:"treasure,omitempty"`
+}
+
+// Visits returns how many times Icarus has entered each room of s.
+func (s Sighting) Visits() [][]int {
+	visits := make([][]int, len(s.Rooms))
+	for y, row := range s.Rooms {
+		visits[y] = make([]int, len(row))
+		for x, r := range row {
+			if r != nil {
+				visits[y][x] = r.Visits
+			}
+		}
+	}
+	return visits
+}
+
+// Wasted returns the number of times Icarus has entered rooms he had already visited.
+func (s Sighting) Wasted() int {
+	n := 0
+	for _, row := range s.Rooms {
+		for _, r := range row {
+			if r != nil && r.Visits > 1 {
+				n += r.Visits - 1
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,121 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"math"
+	"sort"
+)
+
+// Stats summarizes the steps taken to solve mazes.
+type Stats struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+	Median float64 `json:"median"`
+	P90    float64 `json:"p90"`
+	P99    float64 `json:"p99"`
+	// StdDev is the sample standard deviation.
+	StdDev float64 `json:"stddev"`
+	// CILow and CIHigh bound the 95% confidence interval of the mean.
+	CILow  float64 `json:"ci95_low"`
+	CIHigh float64 `json:"ci95_high"`
+	// Ratio is the mean ratio of the steps to the length of the shortest path,
+	// which is 1 for a perfect solver. It is 0 if the lengths are unknown.
+	Ratio float64 `json:"ratio"`
+}
+
+// NewStats computes the statistics of steps.
+// shortest holds the lengths of the shortest paths of the mazes in the same order
+// as steps; it may be nil if they are unknown.
+func NewStats(steps, shortest []int) Stats {
+	n := len(steps)
+	if n == 0 {
+		return Stats{}
+	}
+
+	sorted := make([]int, n)
+	copy(sorted, steps)
+	sort.Ints(sorted)
+
+	st := Stats{
+		Count:  n,
+		Mean:   AvgScores(steps),
+		Min:    sorted[0],
+		Max:    sorted[n-1],
+		Median: percentile(sorted, 50),
+		P90:    percentile(sorted, 90),
+		P99:    percentile(sorted, 99),
+	}
+
+	if n > 1 {
+		var ss float64
+		for _, x := range steps {
+			d := float64(x) - st.Mean
+			ss += d * d
+		}
+		st.StdDev = math.Sqrt(ss / float64(n-1))
+	}
+	margin := tQuantile(n-1) * st.StdDev / math.Sqrt(float64(n))
+	st.CILow, st.CIHigh = st.Mean-margin, st.Mean+margin
+
+	if len(shortest) == n {
+		var sum float64
+		for i, x := range steps {
+			if shortest[i] <= 0 {
+				return st
+			}
+			sum += float64(x) / float64(shortest[i])
+		}
+		st.Ratio = sum / float64(n)
+	}
+
+	return st
+}
+
+// percentile returns the p-th percentile of sorted, interpolating linearly
+// between the closest ranks.
+func percentile(sorted []int, p float64) float64 {
+	pos := p / 100 * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return float64(sorted[len(sorted)-1])
+	}
+	frac := pos - float64(i)
+	return float64(sorted[i]) + frac*float64(sorted[i+1]-sorted[i])
+}
+
+// tTable holds the 97.5th percentiles of Student's t-distribution
+// with 1 to 30 degrees of freedom.
+var tTable = [...]float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tQuantile returns the critical value of a two-sided 95% confidence interval
+// with df degrees of freedom, approximated by the normal distribution above 30.
+func tQuantile(df int) float64 {
+	switch {
+	case df < 1:
+		return 0
+	case df <= len(tTable):
+		return tTable[df-1]
+	default:
+		return 1.960
+	}
+}
@@ -0,0 +1,36 @@
+package mazelib
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewStats(t *testing.T) {
+	tests := []struct {
+		steps, shortest []int
+		want            Stats
+	}{
+		{nil, nil, Stats{}},
+		{[]int{7}, []int{7}, Stats{Count: 1, Mean: 7, Min: 7, Max: 7, Median: 7, P90: 7, P99: 7, CILow: 7, CIHigh: 7, Ratio: 1}},
+		{
+			[]int{4, 1, 3, 2}, []int{2, 1, 3, 1},
+			Stats{
+				Count: 4, Mean: 2.5, Min: 1, Max: 4, Median: 2.5, P90: 3.7, P99: 3.97,
+				StdDev: 1.290994, CILow: 0.446028, CIHigh: 4.553972, Ratio: 1.5,
+			},
+		},
+		// the ratio is unknown without the lengths of the shortest paths
+		{[]int{1, 3}, nil, Stats{Count: 2, Mean: 2, Min: 1, Max: 3, Median: 2, P90: 2.8, P99: 2.98, StdDev: 1.414214, CILow: -10.706, CIHigh: 14.706}},
+	}
+
+	eq := func(a, b float64) bool { return math.Abs(a-b) < 1e-3 }
+	for _, tt := range tests {
+		got := NewStats(tt.steps, tt.shortest)
+		w := tt.want
+		if got.Count != w.Count || got.Min != w.Min || got.Max != w.Max ||
+			!eq(got.Mean, w.Mean) || !eq(got.Median, w.Median) || !eq(got.P90, w.P90) || !eq(got.P99, w.P99) ||
+			!eq(got.StdDev, w.StdDev) || !eq(got.CILow, w.CILow) || !eq(got.CIHigh, w.CIHigh) || !eq(got.Ratio, w.Ratio) {
+			t.Errorf("NewStats(%v, %v) = %+v; want %+v", tt.steps, tt.shortest, got, w)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultCellSize is the size of a room in pixels when none is given.
+const DefaultCellSize = 24
+
+// Colors of the SVG drawing.
+const (
+	svgWallColor     = "#212121"
+	svgStartColor    = "#2e7d32"
+	svgTreasureColor = "#f9a825"
+	svgPathColor     = "#1565c0"
+	svgOptimalColor  = "#c62828"
+)
+
+// SVGOptions configures RenderSVG.
+type SVGOptions struct {
+	// CellSize is the size of a room in pixels. DefaultCellSize is used if it is <= 0.
+	CellSize int
+	// Path is the rooms Icarus went through in order, drawn if not empty.
+	Path []Coordinate
+	// Optimal draws a shortest path from the start to the treasure.
+	Optimal bool
+}
+
+// RenderSVG draws d as an SVG image to w: the walls, the start in green and
+// the treasure in gold, overlaid with Icarus's path in blue and
+// the optimal path in dashed red as requested by opts.
+func RenderSVG(w io.Writer, d MazeData, opts SVGOptions) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	c := opts.CellSize
+	if c <= 0 {
+		c = DefaultCellSize
+	}
+	margin := c / 2
+	// center returns the pixel coordinates of the center of room p.
+	center := func(p Coordinate) (int, int) {
+		return margin + p.X*c + c/2, margin + p.Y*c + c/2
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %[1]d %[2]d">`+"\n",
+		d.Width*c+2*margin, d.Height*c+2*margin)
+	b.WriteString(`<rect width="100%" height="100%" fill="#ffffff"/>` + "\n")
+
+	for _, room := range []struct {
+		p     Coordinate
+		color string
+	}{
+		{d.Start, svgStartColor},
+		{d.Treasure, svgTreasureColor},
+	} {
+		x, y := center(room.p)
+		fmt.Fprintf(&b, `<circle cx="%d" cy="%d" r="%d" fill="%s"/>`+"\n", x, y, c/3, room.color)
+	}
+
+	polyline := func(path []Coordinate, color, dash string) {
+		if len(path) == 0 {
+			return
+		}
+		pts := make([]string, len(path))
+		for i, p := range path {
+			x, y := center(p)
+			pts[i] = fmt.Sprintf("%d,%d", x, y)
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%d" stroke-linecap="round" stroke-linejoin="round" stroke-opacity="0.7"%s/>`+"\n",
+			strings.Join(pts, " "), color, max(c/6, 1), dash)
+	}
+	polyline(opts.Path, svgPathColor, "")
+	if opts.Optimal {
+		polyline(d.ShortestPath(), svgOptimalColor, fmt.Sprintf(` stroke-dasharray="%d,%d"`, c/4, c/4))
+	}
+
+	// each wall is drawn once: the top and left walls of every room,
+	// and the right and bottom walls of the rooms on the edges
+	fmt.Fprintf(&b, `<g stroke="%s" stroke-width="%d" stroke-linecap="square">`+"\n", svgWallColor, max(c/12, 1))
+	line := func(x1, y1, x2, y2 int) {
+		fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", x1, y1, x2, y2)
+	}
+	for y, row := range d.Walls {
+		for x, s := range row {
+			x0, y0 := margin+x*c, margin+y*c
+			if s.Top {
+				line(x0, y0, x0+c, y0)
+			}
+			if s.Left {
+				line(x0, y0, x0, y0+c)
+			}
+			if s.Right && x == d.Width-1 {
+				line(x0+c, y0, x0+c, y0+c)
+			}
+			if s.Bottom && y == d.Height-1 {
+				line(x0, y0+c, x0+c, y0+c)
+			}
+		}
+	}
+	b.WriteString("</g>\n</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,67 @@
+package mazelib
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestShortestPath(t *testing.T) {
+	d := uShaped()
+	want := []Coordinate{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	if got := d.ShortestPath(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// wall the treasure in
+	d.Walls[1][0].Right, d.Walls[1][1].Left = true, true
+	if got := d.ShortestPath(); got != nil {
+		t.Errorf("got %v; want nil", got)
+	}
+}
+
+func TestRenderSVG(t *testing.T) {
+	tests := []struct {
+		opts          SVGOptions
+		wantPolylines int
+	}{
+		{SVGOptions{}, 0},
+		{SVGOptions{Optimal: true}, 1},
+		{SVGOptions{CellSize: 10, Path: []Coordinate{{0, 0}, {1, 0}}, Optimal: true}, 2},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := RenderSVG(&buf, uShaped(), tt.opts); err != nil {
+			t.Fatal(err)
+		}
+
+		count := make(map[string]int)
+		dec := xml.NewDecoder(&buf)
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%+v: invalid SVG: %v", tt.opts, err)
+			}
+			if se, ok := tok.(xml.StartElement); ok {
+				count[se.Name.Local]++
+			}
+		}
+
+		// the U-shaped maze has 9 walls: 8 outer ones and 1 in the middle
+		if count["svg"] != 1 || count["line"] != 9 || count["circle"] != 2 || count["polyline"] != tt.wantPolylines {
+			t.Errorf("%+v: got elements %v", tt.opts, count)
+		}
+	}
+
+	d := uShaped()
+	d.Width = 3
+	if err := RenderSVG(io.Discard, d, SVGOptions{}); err == nil {
+		t.Error("an invalid maze should be an error")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazelib
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TraceVersion is the version of the JSON format of traces written by MarshalTrace.
+const TraceVersion = 1
+
+// Trace is the record of a run of a maze as Daedalus served it:
+// the maze, the reply to the awakening, and every move requested with its reply.
+// Replaying the moves on the same maze must reproduce the same replies
+// but the session IDs.
+type Trace struct {
+	Version int    `json:"version"`
+	Solver  string `json:"solver,omitempty"`
+	// Seed is the seed the maze was generated from.
+	Seed    int64       `json:"seed,omitempty"`
+	Maze    MazeData    `json:"maze"`
+	Started time.Time   `json:"started"`
+	Awake   Reply       `json:"awake"`
+	Moves   []TraceMove `json:"moves"`
+}
+
+// TraceMove is a move requested in a trace.
+type TraceMove struct {
+	// Direction is the direction as requested, which may be invalid.
+	Direction string    `json:"direction"`
+	Reply     Reply     `json:"reply"`
+	Time      time.Time `json:"time"`
+}
+
+// MarshalTrace returns the JSON encoding of t in the current version.
+func MarshalTrace(t Trace) ([]byte, error) {
+	t.Version = TraceVersion
+	if err := t.Maze.Validate(); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// UnmarshalTrace parses a trace encoded in JSON by MarshalTrace and validates its maze.
+func UnmarshalTrace(b []byte) (Trace, error) {
+	var t Trace
+	if err := json.Unmarshal(b, &t); err != nil {
+		return Trace{}, err
+	}
+	if t.Version != TraceVersion {
+		return Trace{}, fmt.Errorf("mazelib: unsupported trace version %d", t.Version)
+	}
+	if err := t.Maze.Validate(); err != nil {
+		return Trace{}, err
+	}
+	return t, nil
+}
@@ -0,0 +1,55 @@
+package mazelib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshalTrace(t *testing.T) {
+	started := time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC)
+	want := Trace{
+		Solver:  "icarus",
+		Seed:    7,
+		Maze:    uShaped(),
+		Started: started,
+		Awake:   Reply{Survey: Survey{Top: true, Bottom: true, Left: true}, Session: "abc", Seed: 7},
+		Moves: []TraceMove{
+			{Direction: "down", Reply: ErrorReply(ErrWall), Time: started.Add(time.Millisecond)},
+			{Direction: "right", Reply: Reply{Survey: Survey{Top: true, Right: true}}, Time: started.Add(2 * time.Millisecond)},
+		},
+	}
+
+	b, err := MarshalTrace(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalTrace(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want.Version = TraceVersion
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+}
+
+func TestUnmarshalTraceErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"version": 2}`, "unsupported trace version 2"},
+		{`{"version": 1, "maze": {"width": 0, "height": 0}}`, "invalid dimensions"},
+		{`{"version": 1`, "unexpected end"},
+	}
+
+	for _, tt := range tests {
+		_, err := UnmarshalTrace([]byte(tt.in))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v; want %q", tt.in, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+package mazepb
+
+import "github.com/skatsuta/labyrinth/mazelib"
+
+// FromSurvey converts a mazelib.Survey to a Survey message.
+func FromSurvey(s mazelib.Survey) *Survey {
+	return &Survey{Top: s.Top, Right: s.Right, Bottom: s.Bottom, Left: s.Left}
+}
+
+// Mazelib converts s to a mazelib.Survey.
+func (s *Survey) Mazelib() mazelib.Survey {
+	return mazelib.Survey{
+		Top:    s.GetTop(),
+		Right:  s.GetRight(),
+		Bottom: s.GetBottom(),
+		Left:   s.GetLeft(),
+	}
+}
+
+// FromReply converts a mazelib.Reply to a Reply message.
+func FromReply(r mazelib.Reply) *Reply {
+	return &Reply{
+		Survey:  FromSurvey(r.Survey),
+		Victory: r.Victory,
+		Message: r.Message,
+		Error:   r.Error,
+		Code:    string(r.Code),
+		Session: r.Session,
+		Seed:    r.Seed,
+	}
+}
+
+// Mazelib converts r to a mazelib.Reply.
+func (r *Reply) Mazelib() mazelib.Reply {
+	return mazelib.Reply{
+		Survey:  r.GetSurvey().Mazelib(),
+		Victory: r.GetVictory(),
+		Message: r.GetMessage(),
+		Error:   r.GetError(),
+		Code:    mazelib.ErrorCode(r.GetCode()),
+		Session: r.GetSession(),
+		Seed:    r.GetSeed(),
+	}
+}
+
+// FromDirection converts a mazelib.Direction to a Direction.
+func FromDirection(d mazelib.Direction) Direction {
+	switch d {
+	case mazelib.N:
+		return Direction_DIRECTION_UP
+	case mazelib.E:
+		return Direction_DIRECTION_RIGHT
+	case mazelib.S:
+		return Direction_DIRECTION_DOWN
+	case mazelib.W:
+		return Direction_DIRECTION_LEFT
+	default:
+		return Direction_DIRECTION_UNSPECIFIED
+	}
+}
+
+// Mazelib converts d to a mazelib.Direction.
+// It returns 0 if d is unspecified.
+func (d Direction) Mazelib() mazelib.Direction {
+	switch d {
+	case Direction_DIRECTION_UP:
+		return mazelib.N
+	case Direction_DIRECTION_RIGHT:
+		return mazelib.E
+	case Direction_DIRECTION_DOWN:
+		return mazelib.S
+	case Direction_DIRECTION_LEFT:
+		return mazelib.W
+	default:
+		return 0
+	}
+}
+
+// Mazelib converts c to a mazelib.Command.
+func (c *Command) Mazelib() mazelib.Command {
+	var cmd mazelib.Command
+	switch c.GetAction() {
+	case Command_ACTION_AWAKE:
+		cmd.Action = mazelib.ActionAwake
+		cmd.Solver = c.GetSolver()
+	case Command_ACTION_MOVE:
+		cmd.Action = mazelib.ActionMove
+		cmd.Direction = c.GetDirection().Mazelib().String()
+	case Command_ACTION_DONE:
+		cmd.Action = mazelib.ActionDone
+	}
+	return cmd
+}
@@ -0,0 +1,641 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+// The Daedalus protocol over gRPC.
+// It mirrors the JSON API served at /awake, /move/:direction, /done and /play.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.29.3
+// source: maze.proto
+
+package mazepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Direction mirrors mazelib.Direction.
+type Direction int32
+
+const (
+	Direction_DIRECTION_UNSPECIFIED Direction = 0
+	Direction_DIRECTION_UP          Direction = 1
+	Direction_DIRECTION_RIGHT       Direction = 2
+	Direction_DIRECTION_DOWN        Direction = 3
+	Direction_DIRECTION_LEFT        Direction = 4
+)
+
+// Enum value maps for Direction.
+var (
+	Direction_name = map[int32]string{
+		0: "DIRECTION_UNSPECIFIED",
+		1: "DIRECTION_UP",
+		2: "DIRECTION_RIGHT",
+		3: "DIRECTION_DOWN",
+		4: "DIRECTION_LEFT",
+	}
+	Direction_value = map[string]int32{
+		"DIRECTION_UNSPECIFIED": 0,
+		"DIRECTION_UP":          1,
+		"DIRECTION_RIGHT":       2,
+		"DIRECTION_DOWN":        3,
+		"DIRECTION_LEFT":        4,
+	}
+)
+
+func (x Direction) Enum() *Direction {
+	p := new(Direction)
+	*p = x
+	return p
+}
+
+func (x Direction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Direction) Descriptor() protoreflect.EnumDescriptor {
+	return file_maze_proto_enumTypes[0].Descriptor()
+}
+
+func (Direction) Type() protoreflect.EnumType {
+	return &file_maze_proto_enumTypes[0]
+}
+
+func (x Direction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Direction.Descriptor instead.
+func (Direction) EnumDescriptor() ([]byte, []int) {
+	return file_maze_proto_rawDescGZIP(), []int{0}
+}
+
+type Command_Action int32
+
+const (
+	Command_ACTION_UNSPECIFIED Command_Action = 0
+	Command_ACTION_AWAKE       Command_Action = 1
+	Command_ACTION_MOVE        Command_Action = 2
+	Command_ACTION_DONE        Command_Action = 3
+)
+
+// Enum value maps for Command_Action.
+var (
+	Command_Action_name = map[int32]string{
+		0: "ACTION_UNSPECIFIED",
+		1: "ACTION_AWAKE",
+		2: "ACTION_MOVE",
+		3: "ACTION_DONE",
+	}
+	Command_Action_value = map[string]int32{
+		"ACTION_UNSPECIFIED": 0,
+		"ACTION_AWAKE":       1,
+		"ACTION_MOVE":        2,
+		"ACTION_DONE":        3,
+	}
+)
+
+func (x Command_Action) Enum() *Command_Action {
+	p := new(Command_Action)
+	*p = x
+	return p
+}
+
+func (x Command_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Command_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_maze_proto_enumTypes[1].Descriptor()
+}
+
+func (Command_Action) Type() protoreflect.EnumType {
+	return &file_maze_proto_enumTypes[1]
+}
+
+func (x Command_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Command_Action.Descriptor instead.
+func (Command_Action) EnumDescriptor() ([]byte, []int) {
+	return file_maze_proto_rawDescGZIP(), []int{5, 0}
+}
+
+// Survey mirrors mazelib.Survey. True indicates a wall is present.
+type Survey struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Top           bool                   `protobuf:"varint,1,opt,name=top,proto3" json:"top,omitempty"`
+	Right         bool                   `protobuf:"varint,2,opt,name=right,proto3" json:"right,omitempty"`
+	Bottom        bool                   `protobuf:"varint,3,opt,name=bottom,proto3" json:"bottom,omitempty"`
+	Left          bool                   `protobuf:"varint,4,opt,name=left,proto3" json:"left,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Survey) Reset() {
+	*x = Survey{}
+	mi := &file_maze_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Survey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Survey) ProtoMessage() {}
+
+func (x *Survey) ProtoReflect() protoreflect.Message {
+	mi := &file_maze_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Survey.ProtoReflect.Descriptor instead.
+func (*Survey) Descriptor() ([]byte, []int) {
+	return file_maze_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Survey) GetTop() bool {
+	if x != nil {
+		return x.Top
+	}
+	return false
+}
+
+func (x *Survey) GetRight() bool {
+	if x != nil {
+		return x.Right
+	}
+	return false
+}
+
+func (x *Survey) GetBottom() bool {
+	if x != nil {
+		return x.Bottom
+	}
+	return false
+}
+
+func (x *Survey) GetLeft() bool {
+	if x != nil {
+		return x.Left
+	}
+	return false
+}
+
+// Reply mirrors mazelib.Reply.
+type Reply struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Survey  *Survey                `protobuf:"bytes,1,opt,name=survey,proto3" json:"survey,omitempty"`
+	Victory bool                   `protobuf:"varint,2,opt,name=victory,proto3" json:"victory,omitempty"`
+	Message string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Error   bool                   `protobuf:"varint,4,opt,name=error,proto3" json:"error,omitempty"`
+	Session string                 `protobuf:"bytes,5,opt,name=session,proto3" json:"session,omitempty"`
+	// code is one of the mazelib.ErrorCode values if error is true.
+	Code string `protobuf:"bytes,6,opt,name=code,proto3" json:"code,omitempty"`
+	// seed is the seed the maze was generated from, returned on awake.
+	Seed          int64 `protobuf:"varint,7,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reply) Reset() {
+	*x = Reply{}
+	mi := &file_maze_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reply) ProtoMessage() {}
+
+func (x *Reply) ProtoReflect() protoreflect.Message {
+	mi := &file_maze_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reply.ProtoReflect.Descriptor instead.
+func (*Reply) Descriptor() ([]byte, []int) {
+	return file_maze_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Reply) GetSurvey() *Survey {
+	if x != nil {
+		return x.Survey
+	}
+	return nil
+}
+
+func (x *Reply) GetVictory() bool {
+	if x != nil {
+		return x.Victory
+	}
+	return false
+}
+
+func (x *Reply) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Reply) GetError() bool {
+	if x != nil {
+		return x.Error
+	}
+	return false
+}
+
+func (x *Reply) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+func (x *Reply) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Reply) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+type AwakeRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Session string                 `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	// solver is the name of the solver recorded in the run history.
+	Solver        string `protobuf:"bytes,2,opt,name=solver,proto3" json:"solver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AwakeRequest) Reset() {
+	*x = AwakeRequest{}
+	mi := &file_maze_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AwakeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AwakeRequest) ProtoMessage() {}
+
+func (x *AwakeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maze_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AwakeRequest.ProtoReflect.Descriptor instead.
+func (*AwakeRequest) Descriptor() ([]byte, []int) {
+	return file_maze_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AwakeRequest) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+func (x *AwakeRequest) GetSolver() string {
+	if x != nil {
+		return x.Solver
+	}
+	return ""
+}
+
+type MoveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       string                 `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	Direction     Direction              `protobuf:"varint,2,opt,name=direction,proto3,enum=labyrinth.Direction" json:"direction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoveRequest) Reset() {
+	*x = MoveRequest{}
+	mi := &file_maze_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveRequest) ProtoMessage() {}
+
+func (x *MoveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maze_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveRequest.ProtoReflect.Descriptor instead.
+func (*MoveRequest) Descriptor() ([]byte, []int) {
+	return file_maze_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MoveRequest) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+func (x *MoveRequest) GetDirection() Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+type DoneRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       string                 `protobuf:"bytes,1,opt,name=session,proto3" json:"session,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DoneRequest) Reset() {
+	*x = DoneRequest{}
+	mi := &file_maze_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DoneRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DoneRequest) ProtoMessage() {}
+
+func (x *DoneRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maze_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DoneRequest.ProtoReflect.Descriptor instead.
+func (*DoneRequest) Descriptor() ([]byte, []int) {
+	return file_maze_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DoneRequest) GetSession() string {
+	if x != nil {
+		return x.Session
+	}
+	return ""
+}
+
+// Command mirrors mazelib.Command.
+type Command struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Action Command_Action         `protobuf:"varint,1,opt,name=action,proto3,enum=labyrinth.Command_Action" json:"action,omitempty"`
+	// direction is used only by ACTION_MOVE.
+	Direction Direction `protobuf:"varint,2,opt,name=direction,proto3,enum=labyrinth.Direction" json:"direction,omitempty"`
+	// solver is used only by ACTION_AWAKE.
+	Solver        string `protobuf:"bytes,3,opt,name=solver,proto3" json:"solver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	mi := &file_maze_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_maze_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_maze_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Command) GetAction() Command_Action {
+	if x != nil {
+		return x.Action
+	}
+	return Command_ACTION_UNSPECIFIED
+}
+
+func (x *Command) GetDirection() Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+func (x *Command) GetSolver() string {
+	if x != nil {
+		return x.Solver
+	}
+	return ""
+}
+
+var File_maze_proto protoreflect.FileDescriptor
+
+const file_maze_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"maze.proto\x12\tlabyrinth\"\\\n" +
+	"\x06Survey\x12\x10\n" +
+	"\x03top\x18\x01 \x01(\bR\x03top\x12\x14\n" +
+	"\x05right\x18\x02 \x01(\bR\x05right\x12\x16\n" +
+	"\x06bottom\x18\x03 \x01(\bR\x06bottom\x12\x12\n" +
+	"\x04left\x18\x04 \x01(\bR\x04left\"\xbe\x01\n" +
+	"\x05Reply\x12)\n" +
+	"\x06survey\x18\x01 \x01(\v2\x11.labyrinth.SurveyR\x06survey\x12\x18\n" +
+	"\avictory\x18\x02 \x01(\bR\avictory\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\bR\x05error\x12\x18\n" +
+	"\asession\x18\x05 \x01(\tR\asession\x12\x12\n" +
+	"\x04code\x18\x06 \x01(\tR\x04code\x12\x12\n" +
+	"\x04seed\x18\a \x01(\x03R\x04seed\"@\n" +
+	"\fAwakeRequest\x12\x18\n" +
+	"\asession\x18\x01 \x01(\tR\asession\x12\x16\n" +
+	"\x06solver\x18\x02 \x01(\tR\x06solver\"[\n" +
+	"\vMoveRequest\x12\x18\n" +
+	"\asession\x18\x01 \x01(\tR\asession\x122\n" +
+	"\tdirection\x18\x02 \x01(\x0e2\x14.labyrinth.DirectionR\tdirection\"'\n" +
+	"\vDoneRequest\x12\x18\n" +
+	"\asession\x18\x01 \x01(\tR\asession\"\xde\x01\n" +
+	"\aCommand\x121\n" +
+	"\x06action\x18\x01 \x01(\x0e2\x19.labyrinth.Command.ActionR\x06action\x122\n" +
+	"\tdirection\x18\x02 \x01(\x0e2\x14.labyrinth.DirectionR\tdirection\x12\x16\n" +
+	"\x06solver\x18\x03 \x01(\tR\x06solver\"T\n" +
+	"\x06Action\x12\x16\n" +
+	"\x12ACTION_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fACTION_AWAKE\x10\x01\x12\x0f\n" +
+	"\vACTION_MOVE\x10\x02\x12\x0f\n" +
+	"\vACTION_DONE\x10\x03*u\n" +
+	"\tDirection\x12\x19\n" +
+	"\x15DIRECTION_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fDIRECTION_UP\x10\x01\x12\x13\n" +
+	"\x0fDIRECTION_RIGHT\x10\x02\x12\x12\n" +
+	"\x0eDIRECTION_DOWN\x10\x03\x12\x12\n" +
+	"\x0eDIRECTION_LEFT\x10\x042\xd4\x01\n" +
+	"\bDaedalus\x122\n" +
+	"\x05Awake\x12\x17.labyrinth.AwakeRequest\x1a\x10.labyrinth.Reply\x120\n" +
+	"\x04Move\x12\x16.labyrinth.MoveRequest\x1a\x10.labyrinth.Reply\x120\n" +
+	"\x04Play\x12\x12.labyrinth.Command\x1a\x10.labyrinth.Reply(\x010\x01\x120\n" +
+	"\x04Done\x12\x16.labyrinth.DoneRequest\x1a\x10.labyrinth.ReplyB&Z$github.com/skatsuta/labyrinth/mazepbb\x06proto3"
+
+var (
+	file_maze_proto_rawDescOnce sync.Once
+	file_maze_proto_rawDescData []byte
+)
+
+func file_maze_proto_rawDescGZIP() []byte {
+	file_maze_proto_rawDescOnce.Do(func() {
+		file_maze_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_maze_proto_rawDesc), len(file_maze_proto_rawDesc)))
+	})
+	return file_maze_proto_rawDescData
+}
+
+var file_maze_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_maze_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_maze_proto_goTypes = []any{
+	(Direction)(0),       // 0: labyrinth.Direction
+	(Command_Action)(0),  // 1: labyrinth.Command.Action
+	(*Survey)(nil),       // 2: labyrinth.Survey
+	(*Reply)(nil),        // 3: labyrinth.Reply
+	(*AwakeRequest)(nil), // 4: labyrinth.AwakeRequest
+	(*MoveRequest)(nil),  // 5: labyrinth.MoveRequest
+	(*DoneRequest)(nil),  // 6: labyrinth.DoneRequest
+	(*Command)(nil),      // 7: labyrinth.Command
+}
+var file_maze_proto_depIdxs = []int32{
+	2, // 0: labyrinth.Reply.survey:type_name -> labyrinth.Survey
+	0, // 1: labyrinth.MoveRequest.direction:type_name -> labyrinth.Direction
+	1, // 2: labyrinth.Command.action:type_name -> labyrinth.Command.Action
+	0, // 3: labyrinth.Command.direction:type_name -> labyrinth.Direction
+	4, // 4: labyrinth.Daedalus.Awake:input_type -> labyrinth.AwakeRequest
+	5, // 5: labyrinth.Daedalus.Move:input_type -> labyrinth.MoveRequest
+	7, // 6: labyrinth.Daedalus.Play:input_type -> labyrinth.Command
+	6, // 7: labyrinth.Daedalus.Done:input_type -> labyrinth.DoneRequest
+	3, // 8: labyrinth.Daedalus.Awake:output_type -> labyrinth.Reply
+	3, // 9: labyrinth.Daedalus.Move:output_type -> labyrinth.Reply
+	3, // 10: labyrinth.Daedalus.Play:output_type -> labyrinth.Reply
+	3, // 11: labyrinth.Daedalus.Done:output_type -> labyrinth.Reply
+	8, // [8:12] is the sub-list for method output_type
+	4, // [4:8] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_maze_proto_init() }
+func file_maze_proto_init() {
+	if File_maze_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_maze_proto_rawDesc), len(file_maze_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_maze_proto_goTypes,
+		DependencyIndexes: file_maze_proto_depIdxs,
+		EnumInfos:         file_maze_proto_enumTypes,
+		MessageInfos:      file_maze_proto_msgTypes,
+	}.Build()
+	File_maze_proto = out.File
+	file_maze_proto_goTypes = nil
+	file_maze_proto_depIdxs = nil
+}
@@ -0,0 +1,264 @@
+// Copyright © 2015 Steve Francia <spf@spf13.com>.
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//
+
+// The Daedalus protocol over gRPC.
+// It mirrors the JSON API served at /awake, /move/:direction, /done and /play.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: maze.proto
+
+package mazepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Daedalus_Awake_FullMethodName = "/labyrinth.Daedalus/Awake"
+	Daedalus_Move_FullMethodName  = "/labyrinth.Daedalus/Move"
+	Daedalus_Play_FullMethodName  = "/labyrinth.Daedalus/Play"
+	Daedalus_Done_FullMethodName  = "/labyrinth.Daedalus/Done"
+)
+
+// DaedalusClient is the client API for Daedalus service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Daedalus hosts labyrinths for Icarus clients to solve.
+type DaedalusClient interface {
+	// Awake initializes a new maze and places Icarus in his awakening location.
+	// If the session is empty, it starts a new session.
+	Awake(ctx context.Context, in *AwakeRequest, opts ...grpc.CallOption) (*Reply, error)
+	// Move moves Icarus one step in the given direction.
+	Move(ctx context.Context, in *MoveRequest, opts ...grpc.CallOption) (*Reply, error)
+	// Play streams commands of a single session and answers each with a reply.
+	// The session ends when the stream is closed.
+	Play(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Command, Reply], error)
+	// Done ends the session and flushes its results.
+	Done(ctx context.Context, in *DoneRequest, opts ...grpc.CallOption) (*Reply, error)
+}
+
+type daedalusClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDaedalusClient(cc grpc.ClientConnInterface) DaedalusClient {
+	return &daedalusClient{cc}
+}
+
+func (c *daedalusClient) Awake(ctx context.Context, in *AwakeRequest, opts ...grpc.CallOption) (*Reply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reply)
+	err := c.cc.Invoke(ctx, Daedalus_Awake_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daedalusClient) Move(ctx context.Context, in *MoveRequest, opts ...grpc.CallOption) (*Reply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reply)
+	err := c.cc.Invoke(ctx, Daedalus_Move_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daedalusClient) Play(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Command, Reply], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Daedalus_ServiceDesc.Streams[0], Daedalus_Play_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Command, Reply]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Daedalus_PlayClient = grpc.BidiStreamingClient[Command, Reply]
+
+func (c *daedalusClient) Done(ctx context.Context, in *DoneRequest, opts ...grpc.CallOption) (*Reply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reply)
+	err := c.cc.Invoke(ctx, Daedalus_Done_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DaedalusServer is the server API for Daedalus service.
+// All implementations must embed UnimplementedDaedalusServer
+// for forward compatibility.
+//
+// Daedalus hosts labyrinths for Icarus clients to solve.
+type DaedalusServer interface {
+	// Awake initializes a new maze and places Icarus in his awakening location.
+	// If the session is empty, it starts a new session.
+	Awake(context.Context, *AwakeRequest) (*Reply, error)
+	// Move moves Icarus one step in the given direction.
+	Move(context.Context, *MoveRequest) (*Reply, error)
+	// Play streams commands of a single session and answers each with a reply.
+	// The session ends when the stream is closed.
+	Play(grpc.BidiStreamingServer[Command, Reply]) error
+	// Done ends the session and flushes its results.
+	Done(context.Context, *DoneRequest) (*Reply, error)
+	mustEmbedUnimplementedDaedalusServer()
+}
+
+// UnimplementedDaedalusServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDaedalusServer struct{}
+
+func (UnimplementedDaedalusServer) Awake(context.Context, *AwakeRequest) (*Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Awake not implemented")
+}
+func (UnimplementedDaedalusServer) Move(context.Context, *MoveRequest) (*Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Move not implemented")
+}
+func (UnimplementedDaedalusServer) Play(grpc.BidiStreamingServer[Command, Reply]) error {
+	return status.Errorf(codes.Unimplemented, "method Play not implemented")
+}
+func (UnimplementedDaedalusServer) Done(context.Context, *DoneRequest) (*Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Done not implemented")
+}
+func (UnimplementedDaedalusServer) mustEmbedUnimplementedDaedalusServer() {}
+func (UnimplementedDaedalusServer) testEmbeddedByValue()                  {}
+
+// UnsafeDaedalusServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DaedalusServer will
+// result in compilation errors.
+type UnsafeDaedalusServer interface {
+	mustEmbedUnimplementedDaedalusServer()
+}
+
+func RegisterDaedalusServer(s grpc.ServiceRegistrar, srv DaedalusServer) {
+	// If the following call pancis, it indicates UnimplementedDaedalusServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Daedalus_ServiceDesc, srv)
+}
+
+func _Daedalus_Awake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AwakeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaedalusServer).Awake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daedalus_Awake_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaedalusServer).Awake(ctx, req.(*AwakeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daedalus_Move_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaedalusServer).Move(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daedalus_Move_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaedalusServer).Move(ctx, req.(*MoveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Daedalus_Play_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DaedalusServer).Play(&grpc.GenericServerStream[Command, Reply]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Daedalus_PlayServer = grpc.BidiStreamingServer[Command, Reply]
+
+func _Daedalus_Done_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DoneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaedalusServer).Done(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Daedalus_Done_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaedalusServer).Done(ctx, req.(*DoneRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Daedalus_ServiceDesc is the grpc.ServiceDesc for Daedalus service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Daedalus_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "labyrinth.Daedalus",
+	HandlerType: (*DaedalusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Awake",
+			Handler:    _Daedalus_Awake_Handler,
+		},
+		{
+			MethodName: "Move",
+			Handler:    _Daedalus_Move_Handler,
+		},
+		{
+			MethodName: "Done",
+			Handler:    _Daedalus_Done_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Play",
+			Handler:       _Daedalus_Play_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "maze.proto",
+}